@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"net"
 	"net/http"
-	"net/http/httputil"
-	"net/url"
 	"os"
 	"os/signal"
 	"strings"
@@ -15,7 +17,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	web "github.com/izinga/robustest-web"
+	"github.com/izinga/robustest-web/internal/app/docs"
+	"github.com/izinga/robustest-web/internal/app/gcproxy"
 	"github.com/izinga/robustest-web/internal/app/handler"
+	"github.com/izinga/robustest-web/internal/app/i18n"
+	"github.com/izinga/robustest-web/internal/app/site"
 	"github.com/joho/godotenv"
 )
 
@@ -38,23 +45,62 @@ func securityHeaders() gin.HandlerFunc {
 		c.Header("Referrer-Policy", "strict-origin-when-cross-origin")
 		// Permissions policy
 		c.Header("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
+		// Keep windows we open or are opened by from scripting each other
+		c.Header("Cross-Origin-Opener-Policy", "same-origin")
+		// Only our own pages may embed our responses, except the images
+		// search results and link previews show
+		resourcePolicy := "same-origin"
+		if p := c.Request.URL.Path; strings.HasPrefix(p, "/assets/") || strings.HasPrefix(p, "/illustrations/") {
+			resourcePolicy = "cross-origin"
+		}
+		c.Header("Cross-Origin-Resource-Policy", resourcePolicy)
 
 		c.Next()
 	}
 }
 
-// cacheControl middleware sets appropriate cache headers for static assets
-func cacheControl() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Set cache headers for static assets (1 year for versioned assets)
-		if strings.HasPrefix(c.Request.URL.Path, "/assets") {
-			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+// printAssets lists the files embedded in the binary.
+func printAssets(w io.Writer) error {
+	for _, tree := range []struct {
+		name string
+		fsys fs.FS
+	}{{"public", web.Public}, {"docs", web.Docs}} {
+		if tree.fsys == nil {
+			fmt.Fprintf(w, "%s: not embedded (build with -tags docsbundle)\n", tree.name)
+			continue
 		}
-		c.Next()
+		files, total := 0, int64(0)
+		err := fs.WalkDir(tree.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%10d  %s/%s\n", info.Size(), tree.name, p)
+			files++
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s: %d files, %d bytes\n", tree.name, files, total)
 	}
+	return nil
 }
 
 func main() {
+	printAssetsFlag := flag.Bool("print-assets", false, "list the files embedded in the binary and exit")
+	flag.Parse()
+	if *printAssetsFlag {
+		if err := printAssets(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Load .env.local first (for local development), then fall back to .env
 	if err := godotenv.Load(".env.local"); err != nil {
 		if err := godotenv.Load(); err != nil {
@@ -69,6 +115,13 @@ func main() {
 
 	r := gin.New()
 
+	// X-Forwarded-For and X-Forwarded-Proto are believed only from
+	// TRUSTED_PROXIES
+	handler.InitTransportSecurity()
+	if err := r.SetTrustedProxies(handler.TrustedProxies()); err != nil {
+		log.Printf("Warning: TRUSTED_PROXIES: %v", err)
+	}
+
 	// Add recovery middleware to recover from panics
 	r.Use(gin.Recovery())
 
@@ -77,17 +130,35 @@ func main() {
 		r.Use(gin.Logger())
 	}
 
+	// gzip/brotli for responses that aren't served pre-encoded
+	r.Use(handler.Compress())
+
 	// Add security headers middleware
 	r.Use(securityHeaders())
+	r.Use(handler.StrictTransportSecurity())
 
-	// Get assets path from environment or use default
-	assetsPath := os.Getenv("ASSETS_PATH")
-	if assetsPath == "" {
-		assetsPath = "./public"
-	}
+	// First-party lead attribution (utm_*, referrer, landing page)
+	r.Use(handler.CaptureAttribution())
+
+	// Redirect unprefixed localized pages to the Accept-Language or
+	// switcher-chosen locale (Vary: Accept-Language)
+	r.Use(handler.NegotiateLocale())
+
+	// ETags, Last-Modified and 304s for pages fixed by the build or by the
+	// synced docs tree
+	r.Use(handler.ConditionalGET(Version))
+
+	// Content-Security-Policy with a per-request script nonce (CSP_MODE);
+	// violations are reported to /csp-report
+	handler.InitCSP()
+	r.Use(handler.ContentSecurityPolicy())
+	r.POST("/csp-report", handler.CSPReport)
 
-	// Static files with cache control
-	r.Static("/assets", assetsPath+"/assets")
+	// Static files, embedded in the binary; ASSETS_PATH serves a public/
+	// tree from disk instead (development)
+	handler.InitAssets(os.Getenv("ASSETS_PATH"), web.Public)
+	r.GET("/assets/*filepath", handler.Assets)
+	r.HEAD("/assets/*filepath", handler.Assets)
 
 	// Health check endpoint for load balancers
 	r.GET("/health", func(c *gin.Context) {
@@ -99,51 +170,100 @@ func main() {
 	})
 
 	// SEO files at root level
-	r.GET("/robots.txt", func(c *gin.Context) {
-		c.File(assetsPath + "/robots.txt")
-	})
+	r.GET("/robots.txt", handler.RobotsTxt)
+	handler.InitSecurityTxt()
+	r.GET("/.well-known/security.txt", handler.SecurityTxt)
+	r.GET("/security.txt", handler.LegacySecurityTxt)
 	r.GET("/sitemap.xml", handler.SitemapXML)
+	r.GET("/sitemaps/pages.xml", handler.SitemapPages)
+	r.GET("/sitemaps/docs.xml", handler.SitemapDocs)
 	r.GET("/llms.txt", handler.LlmsTxt)
+	r.GET("/llms-full.txt", handler.LlmsFullTxt)
+	r.GET("/illustrations/:file", handler.Illustration) // image sitemap entries
 
-	// Routes
-	r.GET("/", handler.HomePage)
-	r.GET("/features", handler.FeaturesPage)
-	r.GET("/platform/manual-testing", handler.ManualTestingPage)
-	r.GET("/platform/test-automation", handler.TestAutomationPage)
-	r.GET("/platform/performance-testing", handler.PerformanceTestingPage)
-	r.GET("/platform/tv-testing", handler.TVTestingPage)
-	r.GET("/platform/network-capture", handler.NetworkCapturePage)
-	r.GET("/platform/device-lab", handler.DeviceLabPage)
-	r.GET("/platform/integrations", handler.IntegrationsPage)
+	// Routes for the registered pages (see handler/site.go), served
+	// pre-rendered once InitPageCache has run. Localized pages are also
+	// served under /ja and /de (see package i18n).
+	docs.UseBundle(web.Docs) // docs built in with -tags docsbundle
 	handler.InitDocs()
-	r.GET("/docs", handler.DocsPage)
+	if path := handler.IndexNowKeyPath(); path != "" {
+		r.GET(path, handler.IndexNowKeyFile) // IndexNow ownership proof
+	}
+	for _, p := range site.Pages() {
+		r.GET(p.Path, handler.Cached(p.Handler))
+		r.GET(handler.MarkdownPath(p.Path), handler.PageMarkdown) // for AI crawlers (llms.txt)
+	}
+	for _, l := range i18n.Locales {
+		if l == i18n.Default {
+			continue
+		}
+		g := r.Group("/"+l.Code, handler.Localize(l))
+		for _, p := range site.Pages() {
+			if p.Localized {
+				g.GET(strings.TrimSuffix(p.Path, "/"), handler.Cached(p.Handler))
+			}
+		}
+	}
 	r.GET("/docs/*path", handler.DocsPage)
-	r.GET("/enterprise", handler.EnterprisePage)
-	r.GET("/partners", handler.PartnersPage)
-	r.GET("/pricing", handler.PricingPage)
-	r.GET("/security", handler.SecurityPage)
-	r.GET("/about", handler.AboutPage)
-	r.GET("/contact", handler.ContactPage)
-	r.GET("/legal", handler.LegalPage)
-
-	// API routes
-	r.POST("/api/contact", handler.SubmitContactForm)
-
-	// First-party beacon for the self-hosted GoatCounter instance
-	// (ground-truth analytics beside Plausible). GoatCounter listens on
-	// localhost only; only its /count endpoint is exposed, under our origin
-	// so ad-blockers and third-party filters never see it.
-	gcTarget, _ := url.Parse("http://127.0.0.1:8081")
-	gcProxy := httputil.NewSingleHostReverseProxy(gcTarget)
-	gcDirector := gcProxy.Director
-	gcProxy.Director = func(req *http.Request) {
-		gcDirector(req)
-		req.Host = "stats.robustest.com" // GoatCounter routes sites by vhost
-	}
-	r.Any("/gc/count", func(c *gin.Context) {
-		c.Request.URL.Path = "/count"
-		gcProxy.ServeHTTP(c.Writer, c.Request)
-	})
+	r.POST("/docs/search-log", handler.DocsSearchLog) // beacon from docs.js
+	r.POST("/docs/feedback", handler.DocsFeedback)
+
+	// Email confirmation and data-subject requests (links from our emails)
+	r.GET("/contact/verify", handler.VerifyEmailPage)
+	r.POST("/contact/verify", handler.VerifyEmail)
+	r.GET("/privacy/request", handler.PrivacyRequestPage)
+	r.POST("/privacy/request", handler.SubmitPrivacyRequest)
+	r.GET("/privacy/export", handler.PrivacyExport)
+	r.GET("/privacy/delete", handler.PrivacyDeletePage)
+	r.POST("/privacy/delete", handler.PrivacyDelete)
+
+	// Demo booking links from invite emails (404 unless booking is configured)
+	r.GET("/booking/cancel", handler.BookingCancelPage)
+	r.POST("/booking/cancel", handler.BookingCancel)
+	r.GET("/booking/reschedule", handler.BookingReschedulePage)
+	r.POST("/booking/reschedule", handler.BookingReschedule)
+
+	// API routes (CORS for allowlisted partner origins)
+	handler.InitLeads()
+	handler.InitBooking()
+	handler.InitAnalytics()
+	handler.InitRetention()
+	handler.InitSitemap()
+	handler.InitPageCache()
+	api := r.Group("/api", handler.ContactCORS())
+	api.POST("/contact", handler.SubmitContactForm)
+	api.POST("/contact/step", handler.ContactFormStep)
+	api.GET("/contact/slots", handler.ContactSlots)
+	api.OPTIONS("/contact", func(c *gin.Context) {}) // preflight, answered by ContactCORS
+	api.GET("/openapi.yaml", handler.ContactOpenAPI)
+	api.POST("/attribution", handler.RecordAttribution) // first touch, from app.js and docs.js
+
+	// Internal reports (basic auth; absent unless ADMIN_PASSWORD is set)
+	admin := r.Group("/admin", handler.AdminAuth())
+	admin.GET("", handler.AdminIndex)
+	admin.GET("/leads/sources", handler.LeadSourcesReport)
+	admin.GET("/analytics", handler.AnalyticsReport)
+	admin.GET("/docs/searches", handler.DocsSearchReport)
+	admin.GET("/docs/feedback", handler.DocsFeedbackReport)
+
+	// First-party analytics beacon, under our origin so ad-blockers and
+	// third-party filters never see it. ANALYTICS_BACKEND=builtin counts it
+	// here and replaces Plausible as the only analytics; otherwise it is
+	// proxied to the self-hosted GoatCounter instance, which listens on
+	// localhost only and exposes nothing but its /count endpoint, beside
+	// Plausible.
+	if handler.BuiltinAnalytics() {
+		r.Any("/gc/count", handler.CollectBeacon)
+	} else {
+		gcConfig, err := gcproxy.ConfigFromEnv()
+		if err != nil {
+			log.Fatalf("GoatCounter proxy: %v", err)
+		}
+		gc := gcproxy.New(gcConfig)
+		r.Any("/gc/count", func(c *gin.Context) {
+			gc.Serve(c.Writer, c.Request, c.ClientIP())
+		})
+	}
 
 	// Handle 404
 	r.NoRoute(handler.NotFoundPage)
@@ -241,6 +361,11 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
+	// Let queued CRM deliveries (and their retries) finish
+	if err := handler.WaitLeads(ctx); err != nil {
+		log.Printf("Pending lead deliveries abandoned: %v", err)
+	}
+	handler.CloseAnalytics()
 
 	log.Println("Server exited")
 }
@@ -0,0 +1,26 @@
+// Package web holds the files the server embeds besides its code, so a
+// release runs from the binary alone: the public/ tree and, in builds
+// tagged docsbundle, a docs snapshot (docs-content/, see make docs-fetch).
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed public/assets public/robots.txt
+var public embed.FS
+
+// Public is the embedded public/ tree (assets/, robots.txt).
+var Public = mustSub(public, "public")
+
+// Docs is the embedded docs snapshot, or nil in builds without one.
+var Docs fs.FS
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,14 @@
+//go:build docsbundle
+
+package web
+
+import "embed"
+
+// all: keeps _sidebar.md and .commit-dates.tsv, which plain patterns skip.
+//
+//go:embed all:docs-content
+var docs embed.FS
+
+func init() {
+	Docs = mustSub(docs, "docs-content")
+}
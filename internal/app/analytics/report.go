@@ -0,0 +1,109 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DocsSearchEvent is the event docs.js sends for a docs search, with the
+// query as its title.
+const DocsSearchEvent = "docs-search"
+
+// topN is the length of each list in a Report.
+const topN = 20
+
+// Count is one row of a report list.
+type Count struct {
+	Name     string `json:"name"`
+	Page     string `json:"page,omitempty"`
+	Count    int    `json:"count"`
+	Visitors int    `json:"visitors"`
+}
+
+// Report summarizes the hits of a period.
+type Report struct {
+	Since     time.Time `json:"since"`
+	Pageviews int       `json:"pageviews"`
+	// Visitors counts distinct visitors per day, summed over the period: a
+	// visitor coming back on another day counts again, as their hash
+	// changed.
+	Visitors     int     `json:"visitors"`
+	Pages        []Count `json:"pages"`
+	Referrers    []Count `json:"referrers"`
+	CTAClicks    []Count `json:"cta_clicks"` // per event and page
+	Events       []Count `json:"events"`
+	DocsSearches []Count `json:"docs_searches"`
+}
+
+// Report summarizes the hits at or after since.
+func (s *Store) Report(since time.Time) (Report, error) {
+	r := Report{Since: since}
+	daily := map[string]bool{}
+	pages, referrers, ctas, events, searches := counter{}, counter{}, counter{}, counter{}, counter{}
+	err := s.Since(since, func(h Hit) {
+		visit := h.Time.UTC().Format(time.DateOnly) + h.Visitor
+		if h.Event {
+			events.add(h.Path, "", visit)
+			switch {
+			case strings.Contains(h.Path, "cta-click"):
+				ctas.add(h.Path, h.Page, visit)
+			case h.Path == DocsSearchEvent && h.Title != "":
+				searches.add(strings.ToLower(h.Title), "", visit)
+			}
+			return
+		}
+		r.Pageviews++
+		daily[visit] = true
+		pages.add(h.Path, "", visit)
+		if h.Referrer != "" {
+			referrers.add(h.Referrer, "", visit)
+		}
+	})
+	r.Visitors = len(daily)
+	r.Pages, r.Referrers = pages.top(), referrers.top()
+	r.CTAClicks, r.Events, r.DocsSearches = ctas.top(), events.top(), searches.top()
+	return r, err
+}
+
+type counterKey struct{ name, page string }
+
+type counterRow struct {
+	count  int
+	visits map[string]bool
+}
+
+// counter tallies hits and distinct daily visitors by name (and page).
+type counter map[counterKey]*counterRow
+
+func (c counter) add(name, page, visit string) {
+	k := counterKey{name, page}
+	row := c[k]
+	if row == nil {
+		row = &counterRow{visits: map[string]bool{}}
+		c[k] = row
+	}
+	row.count++
+	row.visits[visit] = true
+}
+
+// top returns the topN rows, most hits first.
+func (c counter) top() []Count {
+	out := make([]Count, 0, len(c))
+	for k, row := range c {
+		out = append(out, Count{Name: k.name, Page: k.page, Count: row.count, Visitors: len(row.visits)})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Page < out[j].Page
+	})
+	if len(out) > topN {
+		out = out[:topN]
+	}
+	return out
+}
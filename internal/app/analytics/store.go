@@ -0,0 +1,212 @@
+// Package analytics is the site's own page-view and event counter, a
+// stand-in for the self-hosted GoatCounter: it speaks the same beacon
+// protocol (count.js and track() need no changes) and keeps its data in an
+// embedded bbolt database.
+//
+// Nothing stored identifies a visitor. Visitors are counted by a hash of
+// their address and user agent with a salt that changes every UTC day;
+// yesterday's salt is deleted, so hashes can't be linked across days or
+// traced back to an address. Referrers are kept as host names and pages
+// without their query strings.
+package analytics
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	hitsBucket = []byte("hits")
+	metaBucket = []byte("meta")
+	saltKey    = []byte("salt")
+)
+
+// builtin is set when the site counts its own beacons (see UseBuiltin).
+var builtin atomic.Bool
+
+// UseBuiltin records whether this package, rather than GoatCounter, counts
+// the site's beacons. The layouts leave the hosted Plausible script out
+// when it does, so every event is reported once, here.
+func UseBuiltin(on bool) {
+	builtin.Store(on)
+}
+
+// Builtin reports whether the site counts its own beacons.
+func Builtin() bool {
+	return builtin.Load()
+}
+
+// Hit is one page view or event.
+type Hit struct {
+	Time time.Time `json:"time"`
+	// Path is the page viewed, or the event name (e.g. "demo-cta-click").
+	Path  string `json:"path"`
+	Event bool   `json:"event,omitempty"`
+	// Page is the page an event happened on.
+	Page string `json:"page,omitempty"`
+	// Title is the page title, or an event's detail (the query of a
+	// docs-search event).
+	Title string `json:"title,omitempty"`
+	// Referrer is the host a page view came from, or its utm_source.
+	Referrer string `json:"referrer,omitempty"`
+	Visitor  string `json:"visitor"`
+}
+
+// Store keeps hits in a bbolt database.
+type Store struct {
+	db *bolt.DB
+
+	mu      sync.Mutex
+	saltDay string
+	salt    []byte
+}
+
+// Open opens (creating if needed) the database at path.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{hitsBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// OpenFromEnv opens ANALYTICS_DB (default ./data/analytics.db).
+func OpenFromEnv() (*Store, error) {
+	path := os.Getenv("ANALYTICS_DB")
+	if path == "" {
+		path = "./data/analytics.db"
+	}
+	return Open(path)
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Visitor returns the visitor hash for a client address and user agent at
+// t, under the salt of t's UTC day.
+func (s *Store) Visitor(ip, userAgent string, t time.Time) (string, error) {
+	salt, err := s.saltFor(t.UTC().Format(time.DateOnly))
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(ip))
+	h.Write([]byte{0})
+	h.Write([]byte(userAgent))
+	return hex.EncodeToString(h.Sum(nil)[:8]), nil
+}
+
+// saltFor returns the salt of day, replacing the stored one (and so
+// forgetting the previous day's) when day is new.
+func (s *Store) saltFor(day string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saltDay == day {
+		return s.salt, nil
+	}
+	var salt []byte
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		if v := meta.Get(saltKey); len(v) > len(day) && string(v[:len(day)]) == day {
+			salt = append([]byte(nil), v[len(day):]...)
+			return nil
+		}
+		salt = make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		return meta.Put(saltKey, append([]byte(day), salt...))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.saltDay, s.salt = day, salt
+	return salt, nil
+}
+
+// Record stores h. Concurrent calls are batched into one transaction.
+func (s *Store) Record(h Hit) error {
+	raw, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return s.db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(hitsBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(hitKey(h.Time, seq), raw)
+	})
+}
+
+// hitKey orders hits by time; seq keeps hits of the same instant apart.
+func hitKey(t time.Time, seq uint64) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(k[8:], seq)
+	return k
+}
+
+// Purge deletes the hits before cutoff and reports how many there were.
+func (s *Store) Purge(cutoff time.Time) (int, error) {
+	n := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		end := hitKey(cutoff, 0)
+		c := tx.Bucket(hitsBucket).Cursor()
+		// Hits are keyed by time, so the oldest is always first; restart
+		// from it after each delete (Next skips keys after a Delete).
+		for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// Since calls fn for every hit at or after since, oldest first.
+func (s *Store) Since(since time.Time, fn func(Hit)) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(hitsBucket).Cursor()
+		for k, v := c.Seek(hitKey(since, 0)); k != nil; k, v = c.Next() {
+			var h Hit
+			if err := json.Unmarshal(v, &h); err != nil {
+				continue
+			}
+			fn(h)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,38 @@
+package analytics
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPurge(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "analytics.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for _, age := range []time.Duration{500 * 24 * time.Hour, 401 * 24 * time.Hour, 400 * 24 * time.Hour, time.Hour, 0} {
+		for range 2 { // same instant, different sequence numbers
+			if err := s.Record(Hit{Time: now.Add(-age), Path: "/"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	cutoff := now.Add(-400 * 24 * time.Hour)
+	n, err := s.Purge(cutoff)
+	if err != nil || n != 4 {
+		t.Fatalf("Purge = %d, %v; want the 4 hits before the cutoff", n, err)
+	}
+	var left []time.Time
+	s.Since(time.Unix(0, 0), func(h Hit) { left = append(left, h.Time) })
+	if len(left) != 6 || left[0].Before(cutoff) {
+		t.Errorf("left %v, want 6 hits from the cutoff on", left)
+	}
+	if n, err := s.Purge(cutoff); err != nil || n != 0 {
+		t.Errorf("second Purge = %d, %v; want nothing left to purge", n, err)
+	}
+}
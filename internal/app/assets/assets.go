@@ -0,0 +1,100 @@
+// Package assets fingerprints the files served under /assets. Each file is
+// also served at a name carrying a hash of its content (css/app.css as
+// css/app.3f9a1c2e4b.css); pages link to that name through URL, so it can
+// be cached for good: a deploy that changes the file changes its URL.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// hashLen is the number of hex digits of the content hash in a name.
+const hashLen = 10
+
+// Manifest maps asset names to their fingerprinted names and back.
+type Manifest struct {
+	hashed  map[string]string // "css/app.css" -> "css/app.3f9a1c2e4b.css"
+	logical map[string]string // the reverse
+}
+
+// Build fingerprints every file in fsys.
+func Build(fsys fs.FS) (*Manifest, error) {
+	m := &Manifest{hashed: map[string]string{}, logical: map[string]string{}}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(body)
+		ext := path.Ext(name)
+		h := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:hashLen] + ext
+		m.hashed[name] = h
+		m.logical[h] = name
+		return nil
+	})
+	return m, err
+}
+
+// Len is the number of files in the manifest.
+func (m *Manifest) Len() int {
+	return len(m.hashed)
+}
+
+// Resolve maps a requested name to the file to serve. current reports
+// that name is the fingerprint of the file's present content, so the
+// response may be cached indefinitely. A fingerprint from an earlier build
+// (a page cached before a deploy) resolves to the present file without
+// current; anything else resolves to itself.
+func (m *Manifest) Resolve(name string) (file string, current bool) {
+	if file, ok := m.logical[name]; ok {
+		return file, true
+	}
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if i := strings.LastIndexByte(stem, '.'); i >= 0 && isHash(stem[i+1:]) {
+		if _, ok := m.hashed[stem[:i]+ext]; ok {
+			return stem[:i] + ext, false
+		}
+	}
+	return name, false
+}
+
+func isHash(s string) bool {
+	if len(s) != hashLen {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// current is the manifest URL uses (nil until Use).
+var current atomic.Pointer[Manifest]
+
+// Use makes m the manifest URL links to.
+func Use(m *Manifest) {
+	current.Store(m)
+}
+
+// URL returns the path pages should link the asset name ("css/app.css")
+// by: its fingerprinted name, or the plain one if it isn't in the
+// manifest.
+func URL(name string) string {
+	if m := current.Load(); m != nil {
+		if h, ok := m.hashed[name]; ok {
+			return "/assets/" + h
+		}
+	}
+	return "/assets/" + name
+}
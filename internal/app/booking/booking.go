@@ -0,0 +1,262 @@
+// Package booking lets visitors book a demo with a sales engineer: slots
+// are generated from each engineer's working hours in their own time zone,
+// reservations are made transactionally so a slot cannot be sold twice, and
+// each booking comes with an ICS invite.
+package booking
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// Slot is a bookable demo start time. Several engineers may be free at the
+// same time; the visitor only picks the time.
+type Slot struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Scheduler generates slots and makes, moves and cancels bookings.
+type Scheduler struct {
+	cfg   *Config
+	store *Store
+	now   func() time.Time
+}
+
+// NewScheduler returns a scheduler for cfg backed by store.
+func NewScheduler(cfg *Config, store *Store) *Scheduler {
+	return &Scheduler{cfg: cfg, store: store, now: time.Now}
+}
+
+// Duration is the length of one demo.
+func (s *Scheduler) Duration() time.Duration {
+	return time.Duration(s.cfg.SlotMinutes) * time.Minute
+}
+
+// Engineer returns the configured engineer with id.
+func (s *Scheduler) Engineer(id string) (*Engineer, bool) {
+	for i := range s.cfg.Engineers {
+		if s.cfg.Engineers[i].ID == id {
+			return &s.cfg.Engineers[i], true
+		}
+	}
+	return nil, false
+}
+
+// Get returns the booking with id.
+func (s *Scheduler) Get(id string) (Booking, error) {
+	return s.store.Get(id)
+}
+
+// ForEmail returns every booking made with email.
+func (s *Scheduler) ForEmail(email string) ([]Booking, error) {
+	return s.store.ByEmail(email)
+}
+
+// Forget deletes every booking made with email, for erasure requests. It
+// returns the bookings that were still active so their invites can be
+// withdrawn.
+func (s *Scheduler) Forget(email string) ([]Booking, error) {
+	deleted, err := s.store.DeleteByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	var active []Booking
+	now := s.now().UTC()
+	for _, b := range deleted {
+		if b.Active() && b.End.After(now) {
+			b.CanceledAt = &now
+			b.Sequence++
+			active = append(active, b)
+		}
+	}
+	return active, nil
+}
+
+// Purge applies the retention policy to bookings that ended before cutoff
+// (see Store.Purge).
+func (s *Scheduler) Purge(cutoff time.Time, anonymize bool) (int, error) {
+	return s.store.Purge(cutoff, anonymize)
+}
+
+// Slots lists the open slots from the minimum notice to the booking
+// horizon, earliest first.
+func (s *Scheduler) Slots() ([]Slot, error) {
+	all, err := s.store.All()
+	if err != nil {
+		return nil, err
+	}
+	return s.openSlots(all), nil
+}
+
+func (s *Scheduler) openSlots(all []Booking) []Slot {
+	now := s.now()
+	earliest := now.Add(time.Duration(s.cfg.MinNoticeHours) * time.Hour)
+	latest := now.AddDate(0, 0, s.cfg.HorizonDays)
+	dur := s.Duration()
+
+	seen := make(map[int64]bool)
+	var out []Slot
+	for i := range s.cfg.Engineers {
+		e := &s.cfg.Engineers[i]
+		local := now.In(e.loc)
+		for d := -1; d <= s.cfg.HorizonDays+1; d++ {
+			day := time.Date(local.Year(), local.Month(), local.Day()+d, 0, 0, 0, 0, e.loc)
+			for _, w := range e.windows[day.Weekday()] {
+				for m := w.start; m+s.cfg.SlotMinutes <= w.end; m += s.cfg.SlotMinutes {
+					start := time.Date(day.Year(), day.Month(), day.Day(), m/60, m%60, 0, 0, e.loc)
+					end := start.Add(dur)
+					if start.Before(earliest) || start.After(latest) || seen[start.Unix()] {
+						continue
+					}
+					if !e.available(start, end) || busy(all, e.ID, start, end, "") {
+						continue
+					}
+					seen[start.Unix()] = true
+					out = append(out, Slot{Start: start.UTC(), End: end.UTC()})
+				}
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// busy reports whether engineer has an active booking other than except
+// overlapping [start, end).
+func busy(all []Booking, engineer string, start, end time.Time, except string) bool {
+	for _, b := range all {
+		if b.Engineer == engineer && b.ID != except && b.overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// bookable reports whether start is one of the open slots given all.
+func (s *Scheduler) bookable(all []Booking, start time.Time) bool {
+	for _, slot := range s.openSlots(all) {
+		if slot.Start.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// pick chooses who runs a demo at [start, end): the preferred engineer if
+// free, otherwise whoever free has the fewest upcoming demos, so load
+// spreads across the team.
+func (s *Scheduler) pick(all []Booking, start, end time.Time, except, prefer string) *Engineer {
+	upcoming := make(map[string]int)
+	now := s.now()
+	for _, b := range all {
+		if b.Active() && b.Start.After(now) {
+			upcoming[b.Engineer]++
+		}
+	}
+	var best *Engineer
+	for i := range s.cfg.Engineers {
+		e := &s.cfg.Engineers[i]
+		if !e.available(start, end) || busy(all, e.ID, start, end, except) {
+			continue
+		}
+		if e.ID == prefer {
+			return e
+		}
+		if best == nil || upcoming[e.ID] < upcoming[best.ID] {
+			best = e
+		}
+	}
+	return best
+}
+
+// Book reserves b.Start for the visitor in b and returns the stored
+// booking with its ID and engineer filled in. It fails with ErrSlotTaken
+// if the time is not (or no longer) an open slot.
+func (s *Scheduler) Book(b Booking) (Booking, error) {
+	b.Start = b.Start.UTC()
+	b.End = b.Start.Add(s.Duration())
+	err := s.store.update(func(all []Booking) ([]Booking, error) {
+		if !s.bookable(all, b.Start) {
+			return nil, ErrSlotTaken
+		}
+		e := s.pick(all, b.Start, b.End, "", "")
+		if e == nil {
+			return nil, ErrSlotTaken
+		}
+		b.ID = newID()
+		b.Engineer = e.ID
+		b.CreatedAt = s.now().UTC()
+		b.CanceledAt = nil
+		return append(all, b), nil
+	})
+	return b, err
+}
+
+// Reschedule moves booking id to start, keeping its engineer when they are
+// free then.
+func (s *Scheduler) Reschedule(id string, start time.Time) (Booking, error) {
+	var moved Booking
+	err := s.store.update(func(all []Booking) ([]Booking, error) {
+		i := indexOf(all, id)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		if !all[i].Active() {
+			return nil, ErrCanceled
+		}
+		start := start.UTC()
+		end := start.Add(s.Duration())
+		// The booking's own slot counts as free while it moves.
+		others := append(append([]Booking(nil), all[:i]...), all[i+1:]...)
+		if !s.bookable(others, start) {
+			return nil, ErrSlotTaken
+		}
+		e := s.pick(all, start, end, id, all[i].Engineer)
+		if e == nil {
+			return nil, ErrSlotTaken
+		}
+		all[i].Start, all[i].End, all[i].Engineer = start, end, e.ID
+		all[i].Sequence++
+		moved = all[i]
+		return all, nil
+	})
+	return moved, err
+}
+
+// Cancel releases booking id.
+func (s *Scheduler) Cancel(id string) (Booking, error) {
+	var canceled Booking
+	err := s.store.update(func(all []Booking) ([]Booking, error) {
+		i := indexOf(all, id)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		if !all[i].Active() {
+			return nil, ErrCanceled
+		}
+		now := s.now().UTC()
+		all[i].CanceledAt = &now
+		all[i].Sequence++
+		canceled = all[i]
+		return all, nil
+	})
+	return canceled, err
+}
+
+func indexOf(all []Booking, id string) int {
+	for i, b := range all {
+		if b.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func newID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
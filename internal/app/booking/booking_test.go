@@ -0,0 +1,281 @@
+package booking
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testScheduler returns a scheduler for engineers whose clock stands at now,
+// with 30-minute demos, one hour's notice and a horizon of horizonDays.
+func testScheduler(t *testing.T, now time.Time, horizonDays int, engineers ...Engineer) *Scheduler {
+	t.Helper()
+	cfg := &Config{SlotMinutes: 30, MinNoticeHours: 1, HorizonDays: horizonDays, Engineers: engineers}
+	if err := cfg.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	s := NewScheduler(cfg, NewStore(filepath.Join(t.TempDir(), "bookings.json")))
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func engineer(id, tz string, hours map[string][]string, daysOff ...string) Engineer {
+	return Engineer{ID: id, Email: id + "@robustest.com", TimeZone: tz, Hours: hours, DaysOff: daysOff}
+}
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSlots(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		now       string
+		engineers []Engineer
+		want      []string
+	}{
+		{
+			name: "engineers in three time zones",
+			now:  "2026-10-18T12:00:00Z", // a Sunday
+			engineers: []Engineer{
+				engineer("asha", "Asia/Kolkata", map[string][]string{"mon": {"10:00-11:00"}}),
+				engineer("ben", "America/New_York", map[string][]string{"mon": {"09:00-10:00"}}),
+				// Free at the same instants as ben, listed once, until London
+				// falls back on October 25 and New York a week later.
+				engineer("cara", "Europe/London", map[string][]string{"mon": {"14:00-15:00"}}),
+			},
+			want: []string{
+				"2026-10-19T04:30:00Z", "2026-10-19T05:00:00Z", "2026-10-19T13:00:00Z", "2026-10-19T13:30:00Z",
+				"2026-10-26T04:30:00Z", "2026-10-26T05:00:00Z", "2026-10-26T13:00:00Z", "2026-10-26T13:30:00Z",
+				"2026-10-26T14:00:00Z", "2026-10-26T14:30:00Z",
+			},
+		},
+		{
+			name:      "New York springs forward on March 8",
+			now:       "2026-03-01T12:00:00Z",
+			engineers: []Engineer{engineer("ben", "America/New_York", map[string][]string{"mon": {"09:00-09:30"}})},
+			want:      []string{"2026-03-02T14:00:00Z", "2026-03-09T13:00:00Z"},
+		},
+		{
+			name:      "Berlin falls back on October 25",
+			now:       "2026-10-18T12:00:00Z",
+			engineers: []Engineer{engineer("dora", "Europe/Berlin", map[string][]string{"mon": {"09:00-09:30"}})},
+			want:      []string{"2026-10-19T07:00:00Z", "2026-10-26T08:00:00Z"},
+		},
+		{
+			name:      "hours inside the skipped hour give no slots",
+			now:       "2026-03-01T12:00:00Z",
+			engineers: []Engineer{engineer("ben", "America/New_York", map[string][]string{"sun": {"02:00-03:00"}})},
+			want:      []string{"2026-03-15T06:00:00Z", "2026-03-15T06:30:00Z"},
+		},
+		{
+			name: "days off and minimum notice",
+			now:  "2026-10-19T06:15:00Z", // 08:15 in Berlin
+			engineers: []Engineer{
+				engineer("dora", "Europe/Berlin", map[string][]string{"mon": {"09:00-10:00"}}, "2026-10-26"),
+			},
+			want: []string{"2026-10-19T07:30:00Z"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testScheduler(t, utc(tc.now), 14, tc.engineers...)
+			slots, err := s.Slots()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, slot := range slots {
+				if slot.End.Sub(slot.Start) != 30*time.Minute {
+					t.Errorf("slot %v lasts %v", slot.Start, slot.End.Sub(slot.Start))
+				}
+				got = append(got, slot.Start.Format(time.RFC3339))
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("slots = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("slots = %v, want %v", got, tc.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+// twoEngineers overlap at 13:00 and 13:30 UTC on Monday, October 19, 2026;
+// asha alone is free from 14:00 to 15:00.
+func twoEngineers(t *testing.T) *Scheduler {
+	return testScheduler(t, utc("2026-10-18T12:00:00Z"), 7,
+		engineer("asha", "America/New_York", map[string][]string{"mon": {"09:00-11:00"}}),
+		engineer("ben", "Europe/London", map[string][]string{"mon": {"14:00-15:00"}}),
+	)
+}
+
+func TestBook(t *testing.T) {
+	s := twoEngineers(t)
+	for _, tc := range []struct {
+		start        string
+		wantEngineer string
+		wantErr      error
+	}{
+		{"2026-10-19T13:00:00Z", "asha", nil},
+		{"2026-10-19T13:00:00Z", "ben", nil}, // asha is taken
+		{"2026-10-19T13:00:00Z", "", ErrSlotTaken},
+		{"2026-10-19T13:30:00Z", "asha", nil}, // as busy as ben, and listed first
+		{"2026-10-19T14:30:00Z", "asha", nil},
+		{"2026-10-19T14:45:00Z", "", ErrSlotTaken}, // off the slot grid
+		{"2026-10-19T16:00:00Z", "", ErrSlotTaken}, // outside working hours
+		{"2026-10-18T12:30:00Z", "", ErrSlotTaken}, // inside the notice period
+	} {
+		b, err := s.Book(Booking{Start: utc(tc.start), Name: "Ada", Email: "ada@example.com"})
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("Book(%s) error = %v, want %v", tc.start, err, tc.wantErr)
+			continue
+		}
+		if err == nil && (b.Engineer != tc.wantEngineer || b.ID == "" || b.End.Sub(b.Start) != 30*time.Minute) {
+			t.Errorf("Book(%s) = %+v, want a 30-minute demo with %s", tc.start, b, tc.wantEngineer)
+		}
+	}
+}
+
+func TestRescheduleAndCancel(t *testing.T) {
+	// Every case starts from a (asha) and b (ben) at 13:00 and c (asha) at
+	// 14:00.
+	for _, tc := range []struct {
+		name         string
+		op           func(s *Scheduler, ids map[string]string) (Booking, error)
+		wantErr      error
+		wantEngineer string
+		wantSequence int
+	}{
+		{
+			name: "reschedule to a free slot keeps the engineer",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				return s.Reschedule(ids["a"], utc("2026-10-19T14:30:00Z"))
+			},
+			wantEngineer: "asha",
+			wantSequence: 1,
+		},
+		{
+			name: "reschedule to its own time",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				return s.Reschedule(ids["a"], utc("2026-10-19T13:00:00Z"))
+			},
+			wantEngineer: "asha",
+			wantSequence: 1,
+		},
+		{
+			name: "reschedule hands over when the engineer is busy",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				if _, err := s.Cancel(ids["b"]); err != nil {
+					return Booking{}, err
+				}
+				return s.Reschedule(ids["c"], utc("2026-10-19T13:00:00Z"))
+			},
+			wantEngineer: "ben",
+			wantSequence: 1,
+		},
+		{
+			name: "reschedule into a double booking",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				return s.Reschedule(ids["c"], utc("2026-10-19T13:00:00Z"))
+			},
+			wantErr: ErrSlotTaken,
+		},
+		{
+			name: "reschedule onto another engineer's booking",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				return s.Reschedule(ids["a"], utc("2026-10-19T14:00:00Z"))
+			},
+			wantErr: ErrSlotTaken,
+		},
+		{
+			name: "reschedule a canceled booking",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				if _, err := s.Cancel(ids["a"]); err != nil {
+					return Booking{}, err
+				}
+				return s.Reschedule(ids["a"], utc("2026-10-19T14:30:00Z"))
+			},
+			wantErr: ErrCanceled,
+		},
+		{
+			name: "reschedule an unknown booking",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				return s.Reschedule("nope", utc("2026-10-19T14:30:00Z"))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "cancel frees the slot",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				if _, err := s.Cancel(ids["a"]); err != nil {
+					return Booking{}, err
+				}
+				return s.Book(Booking{Start: utc("2026-10-19T13:00:00Z"), Email: "grace@example.com"})
+			},
+			wantEngineer: "asha",
+		},
+		{
+			name: "cancel twice",
+			op: func(s *Scheduler, ids map[string]string) (Booking, error) {
+				if _, err := s.Cancel(ids["b"]); err != nil {
+					return Booking{}, err
+				}
+				return s.Cancel(ids["b"])
+			},
+			wantErr: ErrCanceled,
+		},
+		{
+			name:    "cancel an unknown booking",
+			op:      func(s *Scheduler, ids map[string]string) (Booking, error) { return s.Cancel("nope") },
+			wantErr: ErrNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := twoEngineers(t)
+			ids := make(map[string]string)
+			for _, seed := range []struct{ key, start, engineer string }{
+				{"a", "2026-10-19T13:00:00Z", "asha"},
+				{"b", "2026-10-19T13:00:00Z", "ben"},
+				{"c", "2026-10-19T14:00:00Z", "asha"},
+			} {
+				b, err := s.Book(Booking{Start: utc(seed.start), Email: seed.key + "@example.com"})
+				if err != nil || b.Engineer != seed.engineer {
+					t.Fatalf("seeding %s: %+v, %v", seed.key, b, err)
+				}
+				ids[seed.key] = b.ID
+			}
+
+			got, err := tc.op(s, ids)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Engineer != tc.wantEngineer || got.Sequence != tc.wantSequence {
+				t.Errorf("got %+v, want engineer %s at sequence %d", got, tc.wantEngineer, tc.wantSequence)
+			}
+
+			// Whatever happened, no engineer holds two overlapping demos.
+			all, err := s.store.All()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, x := range all {
+				for _, y := range all[i+1:] {
+					if x.Engineer == y.Engineer && x.Active() && y.overlaps(x.Start, x.End) {
+						t.Errorf("%s holds %s and %s at once", x.Engineer, x.ID, y.ID)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package booking
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the booking setup: how long a demo is, how far ahead visitors
+// may book, and when each sales engineer is available.
+//
+// It is read from a JSON file (BOOKING_CONFIG, default
+// ./config/booking.json), for example:
+//
+//	{
+//	  "slot_minutes": 30,
+//	  "min_notice_hours": 12,
+//	  "horizon_days": 14,
+//	  "engineers": [{
+//	    "id": "asha",
+//	    "name": "Asha Rao",
+//	    "email": "asha@robustest.com",
+//	    "time_zone": "Asia/Kolkata",
+//	    "hours": {"mon": ["10:00-13:00", "14:00-18:00"], "tue": ["10:00-18:00"]},
+//	    "days_off": ["2026-12-25"]
+//	  }]
+//	}
+//
+// Hours are wall-clock times in the engineer's own time zone.
+type Config struct {
+	SlotMinutes    int        `json:"slot_minutes"`
+	MinNoticeHours int        `json:"min_notice_hours"`
+	HorizonDays    int        `json:"horizon_days"`
+	Engineers      []Engineer `json:"engineers"`
+}
+
+// Engineer is one sales engineer who runs demos.
+type Engineer struct {
+	ID       string              `json:"id"`
+	Name     string              `json:"name"`
+	Email    string              `json:"email"`
+	TimeZone string              `json:"time_zone"`
+	Hours    map[string][]string `json:"hours"`
+	DaysOff  []string            `json:"days_off"`
+
+	loc     *time.Location
+	windows map[time.Weekday][]window
+	daysOff map[string]bool
+}
+
+// window is a span of the day in minutes since midnight, [start, end).
+type window struct {
+	start, end int
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// LoadConfig reads and validates the booking config at path.
+func LoadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.prepare(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// ConfigPathFromEnv returns BOOKING_CONFIG or the default path.
+func ConfigPathFromEnv() string {
+	if path := os.Getenv("BOOKING_CONFIG"); path != "" {
+		return path
+	}
+	return "./config/booking.json"
+}
+
+// prepare applies defaults and parses time zones and hours.
+func (cfg *Config) prepare() error {
+	if cfg.SlotMinutes <= 0 {
+		cfg.SlotMinutes = 30
+	}
+	if cfg.MinNoticeHours <= 0 {
+		cfg.MinNoticeHours = 12
+	}
+	if cfg.HorizonDays <= 0 {
+		cfg.HorizonDays = 14
+	}
+	if len(cfg.Engineers) == 0 {
+		return fmt.Errorf("no engineers configured")
+	}
+	seen := make(map[string]bool)
+	for i := range cfg.Engineers {
+		e := &cfg.Engineers[i]
+		if e.ID == "" || e.Email == "" {
+			return fmt.Errorf("engineer %d: id and email are required", i)
+		}
+		if seen[e.ID] {
+			return fmt.Errorf("engineer %q listed twice", e.ID)
+		}
+		seen[e.ID] = true
+		loc, err := time.LoadLocation(e.TimeZone)
+		if err != nil {
+			return fmt.Errorf("engineer %q: %w", e.ID, err)
+		}
+		e.loc = loc
+		e.windows = make(map[time.Weekday][]window)
+		for day, spans := range e.Hours {
+			wd, ok := weekdays[strings.ToLower(day)]
+			if !ok {
+				return fmt.Errorf("engineer %q: unknown day %q", e.ID, day)
+			}
+			for _, span := range spans {
+				w, err := parseWindow(span)
+				if err != nil {
+					return fmt.Errorf("engineer %q: %s: %w", e.ID, day, err)
+				}
+				e.windows[wd] = append(e.windows[wd], w)
+			}
+		}
+		e.daysOff = make(map[string]bool)
+		for _, d := range e.DaysOff {
+			if _, err := time.Parse(time.DateOnly, d); err != nil {
+				return fmt.Errorf("engineer %q: day off %q: want YYYY-MM-DD", e.ID, d)
+			}
+			e.daysOff[d] = true
+		}
+	}
+	return nil
+}
+
+// parseWindow parses "HH:MM-HH:MM".
+func parseWindow(span string) (window, error) {
+	from, to, ok := strings.Cut(span, "-")
+	if !ok {
+		return window{}, fmt.Errorf("%q: want HH:MM-HH:MM", span)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return window{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return window{}, err
+	}
+	if end <= start {
+		return window{}, fmt.Errorf("%q: end must be after start", span)
+	}
+	return window{start, end}, nil
+}
+
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hh < 0 || hh > 24 || mm < 0 || mm > 59 || hh*60+mm > 24*60 {
+		return 0, fmt.Errorf("%q: want HH:MM", s)
+	}
+	return hh*60 + mm, nil
+}
+
+// Location returns the engineer's time zone.
+func (e *Engineer) Location() *time.Location {
+	return e.loc
+}
+
+// available reports whether the engineer works the whole of [start, end).
+func (e *Engineer) available(start, end time.Time) bool {
+	local := start.In(e.loc)
+	if e.daysOff[local.Format(time.DateOnly)] {
+		return false
+	}
+	from := local.Hour()*60 + local.Minute()
+	to := from + int(end.Sub(start)/time.Minute)
+	for _, w := range e.windows[local.Weekday()] {
+		if from >= w.start && to <= w.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package booking
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSlotTaken means no engineer is free for the requested time any
+	// more, usually because someone else booked it first.
+	ErrSlotTaken = errors.New("booking: slot no longer available")
+	// ErrNotFound means no booking has the given ID.
+	ErrNotFound = errors.New("booking: not found")
+	// ErrCanceled means the booking was already canceled.
+	ErrCanceled = errors.New("booking: already canceled")
+)
+
+// Booking is one reserved demo.
+type Booking struct {
+	ID       string    `json:"id"`
+	Engineer string    `json:"engineer"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	// Sequence counts changes to the invite; calendar clients replace an
+	// event only when it grows.
+	Sequence int `json:"sequence"`
+
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Company  string `json:"company,omitempty"`
+	TimeZone string `json:"time_zone,omitempty"`
+	LeadID   string `json:"lead_id,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	CanceledAt *time.Time `json:"canceled_at,omitempty"`
+}
+
+// Active reports whether the booking still holds its slot.
+func (b Booking) Active() bool {
+	return b.CanceledAt == nil
+}
+
+// overlaps reports whether the booking holds any of [start, end).
+func (b Booking) overlaps(start, end time.Time) bool {
+	return b.Active() && b.Start.Before(end) && start.Before(b.End)
+}
+
+// Store keeps bookings in one JSON file. Every change is a transaction:
+// the whole file is read, checked and rewritten under the mutex, and the
+// new version replaces the old with an atomic rename, so two visitors
+// racing for a slot cannot both get it and a crash never leaves half a file.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore returns a store backed by path; the file and its directory are
+// created on first write.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// StoreFromEnv returns the store at BOOKINGS_FILE (default
+// ./data/bookings.json).
+func StoreFromEnv() *Store {
+	path := os.Getenv("BOOKINGS_FILE")
+	if path == "" {
+		path = "./data/bookings.json"
+	}
+	return NewStore(path)
+}
+
+// All returns every booking, canceled ones included.
+func (s *Store) All() ([]Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Get returns the booking with id.
+func (s *Store) Get(id string) (Booking, error) {
+	all, err := s.All()
+	if err != nil {
+		return Booking{}, err
+	}
+	for _, b := range all {
+		if b.ID == id {
+			return b, nil
+		}
+	}
+	return Booking{}, ErrNotFound
+}
+
+// ByEmail returns every booking made with email, canceled ones included.
+func (s *Store) ByEmail(email string) ([]Booking, error) {
+	all, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+	var out []Booking
+	for _, b := range all {
+		if strings.EqualFold(b.Email, email) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// DeleteByEmail removes every booking made with email and returns the
+// removed bookings.
+func (s *Store) DeleteByEmail(email string) ([]Booking, error) {
+	var deleted []Booking
+	err := s.update(func(all []Booking) ([]Booking, error) {
+		kept := all[:0]
+		for _, b := range all {
+			if strings.EqualFold(b.Email, email) {
+				deleted = append(deleted, b)
+				continue
+			}
+			kept = append(kept, b)
+		}
+		return kept, nil
+	})
+	return deleted, err
+}
+
+// Purge applies the retention policy to bookings that ended before cutoff:
+// with anonymize the visitor's details are removed, otherwise the bookings
+// are deleted. It returns how many bookings changed.
+func (s *Store) Purge(cutoff time.Time, anonymize bool) (int, error) {
+	changed := 0
+	err := s.update(func(all []Booking) ([]Booking, error) {
+		kept := all[:0]
+		for _, b := range all {
+			switch {
+			case !b.End.Before(cutoff), anonymize && b.Email == "":
+			case anonymize:
+				b.Name, b.Email, b.Company, b.TimeZone = "", "", "", ""
+				changed++
+			default:
+				changed++
+				continue
+			}
+			kept = append(kept, b)
+		}
+		return kept, nil
+	})
+	return changed, err
+}
+
+// update runs fn on the current bookings and writes back what it returns,
+// all under the lock. If fn fails nothing is written.
+func (s *Store) update(fn func([]Booking) ([]Booking, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all, err = fn(all)
+	if err != nil {
+		return err
+	}
+	return s.write(all)
+}
+
+func (s *Store) read() ([]Booking, error) {
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var all []Booking
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (s *Store) write(all []Booking) error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".bookings-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
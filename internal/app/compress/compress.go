@@ -0,0 +1,212 @@
+// Package compress serves responses gzip- or brotli-encoded according to
+// the client's Accept-Encoding: from variants encoded once up front
+// (pre-rendered pages, static assets) or, for everything else, on the fly.
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Content codings, as sent in Content-Encoding.
+const (
+	Brotli = "br"
+	Gzip   = "gzip"
+)
+
+// Negotiate returns the coding to answer a request with, brotli before
+// gzip, or "" when the client accepts neither (or only with q=0).
+func Negotiate(acceptEncoding string) string {
+	q := qualities(acceptEncoding)
+	for _, coding := range []string{Brotli, Gzip} {
+		if accepts(q, coding) {
+			return coding
+		}
+	}
+	return ""
+}
+
+// Accepts reports whether an Accept-Encoding header accepts coding.
+func Accepts(acceptEncoding, coding string) bool {
+	return accepts(qualities(acceptEncoding), coding)
+}
+
+// qualities parses Accept-Encoding into coding -> q-value.
+func qualities(acceptEncoding string) map[string]float64 {
+	q := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[name] = weight
+	}
+	return q
+}
+
+func accepts(q map[string]float64, coding string) bool {
+	w, listed := q[coding]
+	if !listed {
+		w, listed = q["*"]
+	}
+	return listed && w > 0
+}
+
+// Compressible reports whether responses of contentType are worth
+// encoding: text formats and SVG, not images or archives that are already
+// compressed.
+func Compressible(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mt, "text/") {
+		return true
+	}
+	switch mt {
+	case "application/json", "application/javascript", "application/xml",
+		"application/manifest+json", "application/yaml", "image/svg+xml":
+		return true
+	}
+	return strings.HasSuffix(mt, "+xml") || strings.HasSuffix(mt, "+json")
+}
+
+// NewWriter returns a writer encoding into w with coding at a level cheap
+// enough for per-request use.
+func NewWriter(w io.Writer, coding string) io.WriteCloser {
+	if coding == Brotli {
+		return brotli.NewWriterLevel(w, 5)
+	}
+	gz, _ := gzip.NewWriterLevel(w, gzip.DefaultCompression)
+	return gz
+}
+
+// Variants is a body together with its encoded forms.
+type Variants struct {
+	Identity []byte
+	Gzip     []byte
+	Brotli   []byte
+}
+
+// Precompress encodes body at the best compression levels; it is meant for
+// content encoded once and served many times. An encoded form that is not
+// smaller than body is left out.
+func Precompress(body []byte) Variants {
+	v := Variants{Identity: body}
+	var gz bytes.Buffer
+	gw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	gw.Write(body)
+	gw.Close()
+	if gz.Len() < len(body) {
+		v.Gzip = gz.Bytes()
+	}
+	var br bytes.Buffer
+	bw := brotli.NewWriterLevel(&br, brotli.BestCompression)
+	bw.Write(body)
+	bw.Close()
+	if br.Len() < len(body) {
+		v.Brotli = br.Bytes()
+	}
+	return v
+}
+
+// Pick returns the variant for an Accept-Encoding header and its coding
+// ("" for the identity body).
+func (v Variants) Pick(acceptEncoding string) (body []byte, coding string) {
+	q := qualities(acceptEncoding)
+	switch {
+	case v.Brotli != nil && accepts(q, Brotli):
+		return v.Brotli, Brotli
+	case v.Gzip != nil && accepts(q, Gzip):
+		return v.Gzip, Gzip
+	}
+	return v.Identity, ""
+}
+
+// Spliced is the gzip encoding of a body with fixed-length holes in it,
+// such as a page with a per-request CSP nonce. The text between the holes
+// is deflated once, each piece as a continuation of the ones before with
+// zero bytes in the holes: a match can't span a zero byte, as HTML holds
+// none, so no piece copies from a hole. Fill stores the value of the holes
+// uncompressed between the pieces.
+type Spliced struct {
+	texts    [][]byte // the body split at the holes
+	deflated [][]byte // each text deflated, ending on a sync flush
+	n        int      // the length of a hole's value
+}
+
+// Splice prepares the gzip encoding of body with every occurrence of hole
+// left to be filled with n bytes. body must not contain zero bytes.
+func Splice(body, hole []byte, n int) *Spliced {
+	s := &Spliced{texts: bytes.Split(body, hole), n: n}
+	var history []byte
+	for i, text := range s.texts {
+		if i > 0 {
+			history = append(history, make([]byte, n)...)
+		}
+		var buf bytes.Buffer
+		fw, _ := flate.NewWriterDict(&buf, flate.BestCompression, history)
+		fw.Write(text)
+		fw.Flush() // ends byte-aligned, with the stream left open
+		s.deflated = append(s.deflated, buf.Bytes())
+		history = append(history, text...)
+	}
+	return s
+}
+
+// size is the length of the encoding.
+func (s *Spliced) size() int {
+	size := len(gzipHeader) + 5 + 8
+	for _, d := range s.deflated {
+		size += len(d)
+	}
+	return size + (len(s.texts)-1)*(5+s.n)
+}
+
+// gzipHeader starts a gzip member: deflate, no name or time, best
+// compression, unknown OS.
+var gzipHeader = []byte{0x1f, 0x8b, 8, 0, 0, 0, 0, 0, 2, 255}
+
+// Fill returns the gzip encoding of the body with value in every hole, or
+// false if value is not as long as the holes.
+func (s *Spliced) Fill(value []byte) ([]byte, bool) {
+	if len(value) != s.n {
+		return nil, false
+	}
+	out := make([]byte, 0, s.size())
+	out = append(out, gzipHeader...)
+	crc, size := uint32(0), 0
+	for i, d := range s.deflated {
+		if i > 0 {
+			// A stored (uncompressed) block, not final.
+			n := uint16(len(value))
+			out = append(out, 0, byte(n), byte(n>>8), byte(^n), byte(^n>>8))
+			out = append(out, value...)
+			crc = crc32.Update(crc, crc32.IEEETable, value)
+			size += len(value)
+		}
+		out = append(out, d...)
+		crc = crc32.Update(crc, crc32.IEEETable, s.texts[i])
+		size += len(s.texts[i])
+	}
+	// An empty final stored block, then the gzip trailer.
+	out = append(out, 1, 0, 0, 0xff, 0xff)
+	out = binary.LittleEndian.AppendUint32(out, crc)
+	return binary.LittleEndian.AppendUint32(out, uint32(size)), true
+}
@@ -0,0 +1,44 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSplicedFill(t *testing.T) {
+	page := strings.Repeat(`<p class="text-muted">Real devices, on your premises.</p>`, 200)
+	for _, body := range []string{
+		`<script nonce="HOLE">a()</script>` + page + `<script nonce="HOLE">b()</script>`,
+		"HOLE" + page + "HOLE",
+		page,
+		"",
+	} {
+		s := Splice([]byte(body), []byte("HOLE"), 22)
+		if _, ok := s.Fill([]byte("short")); ok {
+			t.Error("Fill took a value of the wrong length")
+		}
+		// The value repeats text before it, which the encoder must not
+		// have copied from the hole.
+		for _, value := range []string{"r4nd0mN0nce-_r4nd0mN0n", "Real devices, on your "} {
+			encoded, ok := s.Fill([]byte(value))
+			if !ok || len(encoded) != s.size() {
+				t.Errorf("len = %d, size() = %d", len(encoded), s.size())
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(encoded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			zr.Multistream(false)
+			got, err := io.ReadAll(zr) // checks the CRC and length too
+			if err != nil {
+				t.Fatalf("decoding: %v", err)
+			}
+			if want := strings.ReplaceAll(body, "HOLE", value); string(got) != want {
+				t.Errorf("decoded %d bytes, want %d", len(got), len(want))
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package compress
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware encodes responses on the fly for clients that accept it.
+// Bodies shorter than minSize are sent as they are (the headers would eat
+// the saving), as are responses that are not Compressible or already carry
+// a Content-Encoding, such as pre-encoded variants.
+func Middleware(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		coding := Negotiate(c.GetHeader("Accept-Encoding"))
+		if coding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		w := &writer{ResponseWriter: c.Writer, coding: coding, minSize: minSize}
+		c.Writer = w
+		defer w.finish()
+		c.Next()
+	}
+}
+
+// AddVary marks h as depending on Accept-Encoding, keeping any other Vary
+// fields already set.
+func AddVary(h http.Header) {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(f), "Accept-Encoding") {
+				return
+			}
+		}
+	}
+	h.Add("Vary", "Accept-Encoding")
+}
+
+// writer holds back the start of the body until it knows whether it is
+// worth encoding.
+type writer struct {
+	gin.ResponseWriter
+	coding  string
+	minSize int
+
+	buf     []byte
+	decided bool
+	enc     io.WriteCloser // nil: passing through unencoded
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.decided {
+		return w.out().Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow sends the headers as they are: a response that commits
+// them before any body is not encoded.
+func (w *writer) WriteHeaderNow() {
+	if !w.decided {
+		w.commit(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush sends what is buffered, unencoded if no decision was made yet, so
+// streaming responses are not held back.
+func (w *writer) Flush() {
+	if !w.decided {
+		w.commit(false)
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *writer) out() io.Writer {
+	if w.enc != nil {
+		return w.enc
+	}
+	return w.ResponseWriter
+}
+
+// decide starts encoding if the response qualifies, then writes the
+// buffered start of the body.
+func (w *writer) decide() error {
+	h := w.Header()
+	status := w.Status()
+	encode := h.Get("Content-Encoding") == "" &&
+		Compressible(h.Get("Content-Type")) &&
+		status != http.StatusNoContent && status != http.StatusNotModified &&
+		status != http.StatusPartialContent
+	return w.commit(encode)
+}
+
+func (w *writer) commit(encode bool) error {
+	w.decided = true
+	if encode {
+		h := w.Header()
+		h.Set("Content-Encoding", w.coding)
+		h.Del("Content-Length")
+		AddVary(h)
+		// The encoded bytes differ from the ones a strong tag was
+		// computed for; they still mean the same thing.
+		if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.enc = NewWriter(w.ResponseWriter, w.coding)
+	}
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.out().Write(buf)
+	return err
+}
+
+// finish sends a body that stayed under minSize and ends the encoding.
+func (w *writer) finish() {
+	if !w.decided {
+		if len(w.buf) == 0 {
+			return
+		}
+		w.commit(false)
+	}
+	if w.enc != nil {
+		w.enc.Close()
+	}
+}
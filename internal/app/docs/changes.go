@@ -0,0 +1,254 @@
+package docs
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// indexState is what the previous sync published: the tree's SHA and a
+// hash and title of each page's markdown, by /docs URL path. It is kept on
+// disk (<DOCS_DIR>/index.json) so changes shipped with a deploy are
+// noticed across the restart.
+type indexState struct {
+	SHA    string            `json:"sha"`
+	Pages  map[string]string `json:"pages"`
+	Titles map[string]string `json:"titles,omitempty"`
+}
+
+// changelogFile, under DOCS_DIR, keeps one Change per line, oldest first.
+const changelogFile = "changes.jsonl"
+
+// Change is one sync that changed the published docs: the pages added,
+// edited and removed between the previous tree and the new one.
+type Change struct {
+	Time time.Time `json:"time"`
+	// From and SHA identify the trees compared: commit SHAs in GitHub
+	// mode, content fingerprints for bundled docs.
+	From     string        `json:"from"`
+	SHA      string        `json:"sha"`
+	Added    []ChangedPage `json:"added,omitempty"`
+	Modified []ChangedPage `json:"modified,omitempty"`
+	Removed  []ChangedPage `json:"removed,omitempty"`
+}
+
+// ChangedPage is a page in a Change.
+type ChangedPage struct {
+	Path  string `json:"path"` // URL path under /docs ("" is the docs home)
+	Title string `json:"title"`
+}
+
+// Paths lists the /docs URL paths the change touched, sorted.
+func (c Change) Paths() []string {
+	var paths []string
+	for _, list := range [][]ChangedPage{c.Added, c.Modified, c.Removed} {
+		for _, p := range list {
+			paths = append(paths, p.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Summary counts the change's pages: "2 pages updated, 1 added".
+func (c Change) Summary() string {
+	var parts []string
+	for _, part := range []struct {
+		n    int
+		verb string
+	}{{len(c.Modified), "updated"}, {len(c.Added), "added"}, {len(c.Removed), "removed"}} {
+		switch {
+		case part.n == 0:
+		case len(parts) > 0:
+			parts = append(parts, fmt.Sprintf("%d %s", part.n, part.verb))
+		case part.n == 1:
+			parts = append(parts, "1 page "+part.verb)
+		default:
+			parts = append(parts, fmt.Sprintf("%d pages %s", part.n, part.verb))
+		}
+	}
+	if len(parts) == 0 {
+		return "No pages changed"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Changes returns the recorded changelog, newest first, up to limit
+// entries (all for limit <= 0).
+func (s *Store) Changes(limit int) ([]Change, error) {
+	s.changesMu.Lock()
+	defer s.changesMu.Unlock()
+	path := filepath.Join(s.root, changelogFile)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Change
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 4<<20)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var c Change
+		if err := json.Unmarshal(sc.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		out = append(out, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// appendChange adds c to the changelog. The caller holds changesMu.
+func (s *Store) appendChange(c Change) error {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.root, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.root, changelogFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(raw, '\n'))
+	return err
+}
+
+// OnChange registers fn to be told, after a sync, which /docs URL paths
+// ("" is the docs home) were added, edited or removed since the previous
+// synced tree. fn runs in its own goroutine. Nothing is reported for the
+// very first sync, when there is nothing to compare with.
+func (s *Store) OnChange(fn func(paths []string)) {
+	s.mu.Lock()
+	s.onChange = fn
+	s.mu.Unlock()
+}
+
+// publishChanges records the tree at dir as the current index, adds its
+// differences from the previous one to the changelog and reports them.
+func (s *Store) publishChanges(dir, sha string) {
+	s.changesMu.Lock()
+	defer s.changesMu.Unlock()
+	statePath := filepath.Join(s.root, "index.json")
+	var prev indexState
+	raw, err := os.ReadFile(statePath)
+	havePrev := err == nil && json.Unmarshal(raw, &prev) == nil
+	if havePrev && prev.SHA == sha {
+		return
+	}
+	cur := fingerprintPages(dir)
+	cur.SHA = sha
+	if raw, err := json.Marshal(cur); err == nil {
+		if err := os.MkdirAll(s.root, 0o755); err == nil {
+			err = os.WriteFile(statePath, raw, 0o644)
+		}
+		if err != nil {
+			log.Printf("docs: could not save index state: %v", err)
+		}
+	}
+
+	if !havePrev {
+		return
+	}
+	change := diffPages(prev, cur)
+	if len(change.Added)+len(change.Modified)+len(change.Removed) == 0 {
+		return
+	}
+	change.Time = time.Now().UTC()
+	if err := s.appendChange(change); err != nil {
+		log.Printf("docs: could not record changelog: %v", err)
+	}
+
+	s.mu.RLock()
+	fn := s.onChange
+	s.mu.RUnlock()
+	if fn != nil {
+		go fn(change.Paths())
+	}
+}
+
+// fingerprintPages hashes the markdown of the docs home and every sidebar
+// page of the tree at dir, and notes their titles.
+func fingerprintPages(dir string) indexState {
+	paths := []string{""}
+	for _, section := range parseSidebar(filepath.Join(dir, "_sidebar.md")).Sections {
+		for _, link := range section.Links {
+			paths = append(paths, link.Path)
+		}
+	}
+	state := indexState{Pages: map[string]string{}, Titles: map[string]string{}}
+	for _, p := range paths {
+		raw, err := readSource(dir, p)
+		if err != nil {
+			continue
+		}
+		sum := sha256.Sum256(raw)
+		state.Pages[p] = hex.EncodeToString(sum[:])
+		state.Titles[p] = sourceTitle(raw)
+	}
+	return state
+}
+
+// sourceTitle is a page's title as renderPage gives it: its first h1.
+func sourceTitle(raw []byte) string {
+	for _, m := range headingRe.FindAllStringSubmatch(string(raw), -1) {
+		if len(m[1]) == 1 {
+			return stripMD(m[2])
+		}
+	}
+	return "Documentation"
+}
+
+// diffPages lists the pages added, edited or removed between two indexes,
+// each list sorted by path.
+func diffPages(prev, cur indexState) Change {
+	c := Change{From: prev.SHA, SHA: cur.SHA}
+	for p, h := range cur.Pages {
+		old, ok := prev.Pages[p]
+		switch {
+		case !ok:
+			c.Added = append(c.Added, ChangedPage{Path: p, Title: cur.Titles[p]})
+		case old != h:
+			c.Modified = append(c.Modified, ChangedPage{Path: p, Title: cur.Titles[p]})
+		}
+	}
+	for p := range prev.Pages {
+		if _, ok := cur.Pages[p]; !ok {
+			title := prev.Titles[p]
+			if title == "" {
+				title = p // index saved before titles were kept
+			}
+			c.Removed = append(c.Removed, ChangedPage{Path: p, Title: title})
+		}
+	}
+	for _, list := range [][]ChangedPage{c.Added, c.Modified, c.Removed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	}
+	return c
+}
@@ -0,0 +1,112 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeTree writes files (relative path -> content) under a new directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for rel, content := range files {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const testSidebar = `* **Guides**
+  * [Setup](guides/setup.md)
+  * [Devices](guides/devices.md)
+  * [Legacy](guides/legacy.md)
+`
+
+func TestPublishChangesReportsDiff(t *testing.T) {
+	s := &Store{root: t.TempDir()}
+	reported := make(chan []string, 1)
+	s.OnChange(func(paths []string) { reported <- paths })
+
+	first := writeTree(t, map[string]string{
+		"_sidebar.md":       testSidebar,
+		"README.md":         "# Docs\n",
+		"guides/setup.md":   "# Setup\nInstall it.\n",
+		"guides/devices.md": "# Devices\n",
+		"guides/legacy.md":  "# Legacy\n",
+	})
+	s.publishChanges(first, "sha1")
+	select {
+	case paths := <-reported:
+		t.Fatalf("first sync reported %q; there is nothing to compare it with", paths)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	second := writeTree(t, map[string]string{
+		"_sidebar.md": `* **Guides**
+  * [Setup](guides/setup.md)
+  * [Devices](guides/devices.md)
+  * [Grids](guides/grids.md)
+`,
+		"README.md":         "# Docs\n",
+		"guides/setup.md":   "# Setup\nInstall it, then log in.\n",
+		"guides/devices.md": "# Devices\n",
+		"guides/grids.md":   "# Grids\n",
+	})
+	s.publishChanges(second, "sha2")
+	select {
+	case paths := <-reported:
+		want := []string{"guides/grids", "guides/legacy", "guides/setup"}
+		if !reflect.DeepEqual(paths, want) {
+			t.Errorf("reported %q, want %q", paths, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second sync reported nothing")
+	}
+
+	changes, err := s.Changes(10)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("Changes = %v, %v; want one entry", changes, err)
+	}
+	c := changes[0]
+	if c.From != "sha1" || c.SHA != "sha2" {
+		t.Errorf("change compares %s..%s, want sha1..sha2", c.From, c.SHA)
+	}
+	for _, tc := range []struct {
+		name string
+		got  []ChangedPage
+		want []ChangedPage
+	}{
+		{"added", c.Added, []ChangedPage{{Path: "guides/grids", Title: "Grids"}}},
+		{"modified", c.Modified, []ChangedPage{{Path: "guides/setup", Title: "Setup"}}},
+		{"removed", c.Removed, []ChangedPage{{Path: "guides/legacy", Title: "Legacy"}}},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+
+	// Publishing the same SHA again is a no-op.
+	s.publishChanges(second, "sha2")
+	select {
+	case paths := <-reported:
+		t.Errorf("republishing sha2 reported %q", paths)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDiffPagesTitlesOfOldIndexes(t *testing.T) {
+	prev := indexState{SHA: "a", Pages: map[string]string{"old": "1"}}
+	cur := indexState{SHA: "b", Pages: map[string]string{}}
+	c := diffPages(prev, cur)
+	if want := []ChangedPage{{Path: "old", Title: "old"}}; !reflect.DeepEqual(c.Removed, want) {
+		t.Errorf("removed = %v, want %v", c.Removed, want)
+	}
+}
@@ -0,0 +1,215 @@
+package docs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// History is what the docs repo's git log says about one page's source
+// file.
+type History struct {
+	// Updated is the committer date of the last commit touching the file.
+	Updated time.Time
+	// Author is who wrote that commit.
+	Author string
+	// Contributors are the authors of the file's commits, most recent first.
+	Contributors []string
+}
+
+// historyFile is the manifest of each markdown file's history, kept at the
+// root of a synced tree: one line per file with the path, the last commit
+// date (RFC 3339), its author and then every contributor, tab-separated.
+// make docs-fetch writes it for bundled docs; GitHub syncs write their own.
+// Lines with only a path and date (older manifests) still give the date.
+const historyFile = ".commit-dates.tsv"
+
+// historyCacheFile, under DOCS_DIR, keeps the history fetched for each
+// file by a hash of its content, so a GitHub sync only asks about the
+// files that changed since the last one.
+const historyCacheFile = "history.json"
+
+// maxHistoryCommits is how far back contributors are collected per file
+// (one page of the commits API).
+const maxHistoryCommits = 100
+
+// LastModified returns when the file behind a /docs URL path was last
+// committed, or the zero time if the tree has no date for it.
+func (s *Store) LastModified(urlPath string) time.Time {
+	return s.History(urlPath).Updated
+}
+
+// History returns the history of the file behind a /docs URL path ("" is
+// the docs home); the zero History if the tree has none for it.
+func (s *Store) History(urlPath string) History {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rel := strings.Trim(urlPath, "/")
+	if rel == "" {
+		rel = "README"
+	}
+	if h, ok := s.history[rel+".md"]; ok {
+		return h
+	}
+	return s.history[rel+"/README.md"]
+}
+
+// cachedHistory is one entry of the history cache.
+type cachedHistory struct {
+	Hash    string  `json:"hash"`
+	History History `json:"history"`
+}
+
+// writeHistory writes the history manifest for the tree at sha into dir,
+// asking GitHub about each markdown file whose content is not in the
+// cache. Files GitHub can't answer for are left out and retried on the
+// next sync.
+func (s *Store) writeHistory(dir, sha string) error {
+	cachePath := filepath.Join(s.root, historyCacheFile)
+	cache := map[string]cachedHistory{}
+	if raw, err := os.ReadFile(cachePath); err == nil {
+		json.Unmarshal(raw, &cache)
+	}
+	next := map[string]cachedHistory{}
+	var b strings.Builder
+	fetched, failed := 0, 0
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".md") {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(raw)
+		hash := hex.EncodeToString(sum[:])
+		entry, ok := cache[rel]
+		if !ok || entry.Hash != hash {
+			h, err := s.fileHistory(rel, sha)
+			if err != nil {
+				log.Printf("docs: history of %s: %v", rel, err)
+				failed++
+				return nil
+			}
+			entry = cachedHistory{Hash: hash, History: h}
+			fetched++
+		}
+		next[rel] = entry
+		b.WriteString(historyLine(rel, entry.History))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if raw, err := json.Marshal(next); err == nil {
+		if err := os.WriteFile(cachePath, raw, 0o644); err != nil {
+			log.Printf("docs: could not save history cache: %v", err)
+		}
+	}
+	if fetched > 0 || failed > 0 {
+		log.Printf("docs: history: %d files fetched, %d cached, %d failed", fetched, len(next)-fetched, failed)
+	}
+	return os.WriteFile(filepath.Join(dir, historyFile), []byte(b.String()), 0o644)
+}
+
+// fileHistory asks GitHub for the commits at or before sha that touched
+// the repo file rel.
+func (s *Store) fileHistory(rel, sha string) (History, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/commits?path=%s&sha=%s&per_page=%d", s.repo, neturl.QueryEscape(rel), sha, maxHistoryCommits)
+	resp, err := s.apiRequest(http.MethodGet, url)
+	if err != nil {
+		return History{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return History{}, fmt.Errorf("github commits API: %s", resp.Status)
+	}
+	var out []struct {
+		Commit struct {
+			Author struct {
+				Name string `json:"name"`
+			} `json:"author"`
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return History{}, err
+	}
+	if len(out) == 0 {
+		return History{}, fmt.Errorf("no commits")
+	}
+	h := History{Updated: out[0].Commit.Committer.Date, Author: out[0].Commit.Author.Name}
+	for _, c := range out {
+		h.Contributors = appendNew(h.Contributors, c.Commit.Author.Name)
+	}
+	return h, nil
+}
+
+// historyLine is rel's line in the history manifest.
+func historyLine(rel string, h History) string {
+	fields := append([]string{rel, h.Updated.Format(time.RFC3339), h.Author}, h.Contributors...)
+	for i, f := range fields {
+		fields[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(f)
+	}
+	return strings.Join(fields, "\t") + "\n"
+}
+
+// readHistory loads dir's manifest; nil if there is none.
+func readHistory(dir string) map[string]History {
+	raw, err := os.ReadFile(filepath.Join(dir, historyFile))
+	if err != nil {
+		return nil
+	}
+	history := map[string]History{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(fields[1]))
+		if err != nil {
+			continue
+		}
+		h := History{Updated: t}
+		if len(fields) > 2 {
+			h.Author = strings.TrimSpace(fields[2])
+		}
+		for _, name := range fields[min(len(fields), 3):] {
+			h.Contributors = appendNew(h.Contributors, strings.TrimSpace(name))
+		}
+		if h.Author != "" && len(h.Contributors) == 0 {
+			h.Contributors = []string{h.Author}
+		}
+		history[fields[0]] = h
+	}
+	return history
+}
+
+// appendNew appends name to names unless it is empty or already there.
+func appendNew(names []string, name string) []string {
+	if name == "" {
+		return names
+	}
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
@@ -21,6 +21,12 @@ type Page struct {
 	Path    string // URL path under /docs, e.g. "admin/healthpage"
 	Content template.HTML
 	TOC     []TOCItem
+	// Markdown is the page source with links and images made absolute
+	// (links to the .md variants), so it stands alone when quoted.
+	Markdown string
+	// History is the last change to the page's source file and who has
+	// edited it; zero when the tree has no history manifest.
+	History History
 }
 
 // TOCItem is one heading in a page's table of contents.
@@ -191,53 +197,45 @@ func (s *Store) Load(urlPath string) (*Page, error) {
 		return v.(*Page), nil
 	}
 
-	rel := urlPath
-	if rel == "" {
-		rel = "README"
-	}
-	file := filepath.Join(dir, rel+".md")
-	raw, err := os.ReadFile(file)
+	raw, err := readSource(dir, urlPath)
 	if err != nil {
-		// A directory link may mean section README, e.g. guides/ -> guides/README.md
-		alt := filepath.Join(dir, rel, "README.md")
-		if raw2, err2 := os.ReadFile(alt); err2 == nil {
-			raw = raw2
-		} else {
-			return nil, os.ErrNotExist
-		}
+		return nil, err
 	}
 
-	page, err := renderPage(raw, urlPath)
+	page, err := renderPage(raw, urlPath, s.siteURL)
 	if err != nil {
 		return nil, err
 	}
+	page.History = s.History(urlPath)
 	s.pageCache.Store(cacheKey, page)
 	return page, nil
 }
 
+// readSource reads the markdown behind a /docs URL path in the tree at dir.
+func readSource(dir, urlPath string) ([]byte, error) {
+	rel := urlPath
+	if rel == "" {
+		rel = "README"
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, rel+".md"))
+	if err != nil {
+		// A directory link may mean section README, e.g. guides/ -> guides/README.md
+		if raw, err := os.ReadFile(filepath.Join(dir, rel, "README.md")); err == nil {
+			return raw, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	return raw, nil
+}
+
 var (
 	headingRe = regexp.MustCompile(`(?m)^(#{1,3})\s+(.+?)\s*$`)
 	mdLinkRe  = regexp.MustCompile(`\]\(([^)#]+\.md)(#[^)]*)?\)`)
 	imgSrcRe  = regexp.MustCompile(`(src="|\]\()(?:\.\./|\./)*(assets/[^")]+)`)
 )
 
-func renderPage(raw []byte, urlPath string) (*Page, error) {
-	src := string(raw)
-
-	// Rewrite .md links to /docs routes. The repo follows the docsify
-	// convention of repo-root-relative targets ("guides/x.md").
-	src = mdLinkRe.ReplaceAllStringFunc(src, func(m string) string {
-		parts := mdLinkRe.FindStringSubmatch(m)
-		target, frag := strings.TrimPrefix(parts[1], "./"), parts[2]
-		p := docPathFromLink(target)
-		if p == "" {
-			return "](/docs" + frag + ")"
-		}
-		return "](/docs/" + p + frag + ")"
-	})
-
-	// Rewrite asset references to the synced-assets route.
-	src = imgSrcRe.ReplaceAllString(src, `$1/docs/$2`)
+func renderPage(raw []byte, urlPath, siteURL string) (*Page, error) {
+	src := rewriteLinks(string(raw), "", "")
 
 	// Title = first h1; TOC = h2/h3.
 	title := ""
@@ -262,13 +260,31 @@ func renderPage(raw []byte, urlPath string) (*Page, error) {
 		return nil, err
 	}
 	return &Page{
-		Title:   title,
-		Path:    urlPath,
-		Content: template.HTML(buf.String()),
-		TOC:     toc,
+		Title:    title,
+		Path:     urlPath,
+		Content:  template.HTML(buf.String()),
+		TOC:      toc,
+		Markdown: rewriteLinks(string(raw), siteURL, ".md"),
 	}, nil
 }
 
+// rewriteLinks points .md links at /docs routes (with suffix appended) and
+// asset references at the synced-assets route, both under base ("" for
+// root-relative URLs). The repo follows the docsify convention of
+// repo-root-relative targets ("guides/x.md").
+func rewriteLinks(src, base, suffix string) string {
+	src = mdLinkRe.ReplaceAllStringFunc(src, func(m string) string {
+		parts := mdLinkRe.FindStringSubmatch(m)
+		target, frag := strings.TrimPrefix(parts[1], "./"), parts[2]
+		p := docPathFromLink(target)
+		if p == "" {
+			return "](" + base + "/docs" + suffix + frag + ")"
+		}
+		return "](" + base + "/docs/" + p + suffix + frag + ")"
+	})
+	return imgSrcRe.ReplaceAllString(src, `${1}`+base+`/docs/$2`)
+}
+
 var nonIDChars = regexp.MustCompile(`[^a-z0-9\- ]`)
 
 // headingID mirrors goldmark's auto heading ID generation closely enough
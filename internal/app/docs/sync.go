@@ -31,16 +31,52 @@ type Store struct {
 	dir      string // directory containing the current synced tree
 	sha      string // commit SHA of the current tree
 	syncedAt time.Time
-	lastErr  error
+	// changedAt is when the served tree last changed (syncedAt also moves
+	// on syncs that find nothing new).
+	changedAt time.Time
+	lastErr   error
 
 	repo   string
 	branch string
 	token  string
 	root   string // parent dir under which synced trees are extracted
 	local  string // non-empty: serve a bundled docs dir, never call GitHub
+	// siteURL is the public base URL for absolute links in Page.Markdown.
+	siteURL string
 
 	pageCache sync.Map // key string -> *Page (invalidated on new SHA)
 	navCache  *Nav
+	// history maps repo-relative file paths to their last commit and
+	// contributors, from the tree's history manifest.
+	history  map[string]History
+	onChange func(paths []string) // see OnChange
+	// changesMu serializes publishChanges and the changelog it writes.
+	changesMu sync.Mutex
+}
+
+// bundle is the docs snapshot built into the binary (see UseBundle).
+var bundle fs.FS
+
+// UseBundle makes fsys, a tree laid out like docs-content/, the docs to
+// serve when no docs directory is on disk. Call it before NewStore.
+func UseBundle(fsys fs.FS) {
+	bundle = fsys
+}
+
+// unpackBundle writes the bundled snapshot to <root>/bundled, where the
+// store reads it like any bundled directory, and returns that path ("" on
+// failure).
+func unpackBundle(root string) string {
+	dir := filepath.Join(root, "bundled")
+	err := os.RemoveAll(dir)
+	if err == nil {
+		err = os.CopyFS(dir, bundle)
+	}
+	if err != nil {
+		log.Printf("docs: could not unpack the built-in docs: %v", err)
+		return ""
+	}
+	return dir
 }
 
 // NewStore configures the docs store from the environment.
@@ -66,12 +102,20 @@ func NewStore() *Store {
 	if fi, err := os.Stat(local); err != nil || !fi.IsDir() {
 		local = ""
 	}
+	if local == "" && bundle != nil {
+		local = unpackBundle(root)
+	}
+	siteURL := os.Getenv("SITE_URL")
+	if siteURL == "" {
+		siteURL = "https://robustest.com"
+	}
 	return &Store{
-		repo:   repo,
-		branch: branch,
-		token:  os.Getenv("DOCS_GITHUB_TOKEN"),
-		root:   root,
-		local:  local,
+		repo:    repo,
+		branch:  branch,
+		token:   os.Getenv("DOCS_GITHUB_TOKEN"),
+		root:    root,
+		local:   local,
+		siteURL: strings.TrimRight(siteURL, "/"),
 	}
 }
 
@@ -120,6 +164,14 @@ func (s *Store) Status() (sha string, syncedAt time.Time, lastErr error) {
 	return s.sha, s.syncedAt, s.lastErr
 }
 
+// Published returns the SHA of the served tree and when it was put in
+// service, the validators for pages rendered from it.
+func (s *Store) Published() (sha string, at time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sha, s.changedAt
+}
+
 // Dir returns the current synced tree directory (empty until first sync).
 func (s *Store) Dir() string {
 	s.mu.RLock()
@@ -203,14 +255,22 @@ func (s *Store) Sync() error {
 		s.recordErr(err)
 		return err
 	}
+	if err := s.writeHistory(dest, sha); err != nil {
+		// Not fatal: undated pages show no "last updated" and the sitemap
+		// leaves lastmod out for them.
+		log.Printf("docs: history: %v", err)
+	}
+	history := readHistory(dest)
 
 	s.mu.Lock()
 	old := s.dir
 	s.dir = dest
 	s.sha = sha
 	s.syncedAt = time.Now()
+	s.changedAt = s.syncedAt
 	s.lastErr = nil
 	s.navCache = nil
+	s.history = history
 	s.mu.Unlock()
 	s.pageCache = sync.Map{}
 
@@ -218,6 +278,7 @@ func (s *Store) Sync() error {
 		os.RemoveAll(old)
 	}
 	log.Printf("docs: synced %s@%s", s.repo, sha[:12])
+	s.publishChanges(dest, sha)
 	return nil
 }
 
@@ -244,6 +305,7 @@ func (s *Store) syncLocal() error {
 		return err
 	}
 	sha := hex.EncodeToString(h.Sum(nil))
+	history := readHistory(s.local)
 
 	s.mu.Lock()
 	changed := sha != s.sha
@@ -252,11 +314,14 @@ func (s *Store) syncLocal() error {
 	s.syncedAt = time.Now()
 	s.lastErr = nil
 	if changed {
+		s.changedAt = s.syncedAt
 		s.navCache = nil
+		s.history = history
 	}
 	s.mu.Unlock()
 	if changed {
 		s.pageCache = sync.Map{}
+		s.publishChanges(s.local, sha)
 	}
 	return nil
 }
@@ -0,0 +1,291 @@
+// Package docsearch records what readers search for in the docs, how many
+// results each search found and which result (if any) they opened, so the
+// docs team can see which pages are missing or hard to find. The search
+// itself runs in the browser (docs.js over /docs/index.json), which reports
+// each search here with a beacon.
+package docsearch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry is one reported search.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// SHA is the docs tree that was published when the search ran.
+	SHA     string `json:"sha"`
+	Query   string `json:"query"`
+	Results int    `json:"results"`
+	// Clicked is the URL path of the result opened ("" for none).
+	Clicked string `json:"clicked,omitempty"`
+	// Counted marks the click of a search already reported on its own, so
+	// it adds a click but not another search.
+	Counted bool `json:"counted,omitempty"`
+}
+
+// Log keeps entries as JSON lines in a single file.
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLog returns a log backed by path; the file and its directory are
+// created on first write.
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// LogFromEnv returns the log at DOCS_SEARCH_LOG (default
+// ./data/docs-searches.jsonl).
+func LogFromEnv() *Log {
+	path := os.Getenv("DOCS_SEARCH_LOG")
+	if path == "" {
+		path = "./data/docs-searches.jsonl"
+	}
+	return NewLog(path)
+}
+
+// Append records e.
+func (l *Log) Append(e Entry) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(raw, '\n'))
+	return err
+}
+
+// All returns every entry, oldest first.
+func (l *Log) All() ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.read()
+}
+
+func (l *Log) read() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Entry
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", l.path, n, err)
+		}
+		out = append(out, e)
+	}
+	return out, sc.Err()
+}
+
+// Purge deletes the searches against docs SHAs that were last searched
+// before cutoff, other than current (the SHA published now), and reports
+// how many it deleted. A SHA's searches go together, so its report is
+// either complete or gone.
+func (l *Log) Purge(cutoff time.Time, current string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	all, err := l.read()
+	if err != nil {
+		return 0, err
+	}
+	drop := map[string]bool{}
+	for _, row := range SHAs(all) {
+		drop[row.SHA] = row.SHA != current && row.Last.Before(cutoff)
+	}
+	kept := all[:0]
+	for _, e := range all {
+		if !drop[e.SHA] {
+			kept = append(kept, e)
+		}
+	}
+	n := len(all) - len(kept)
+	if n == 0 {
+		return 0, nil
+	}
+	return n, l.write(kept)
+}
+
+// write replaces the file with entries.
+func (l *Log) write(entries []Entry) error {
+	dir := filepath.Dir(l.path)
+	tmp, err := os.CreateTemp(dir, ".docs-searches-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
+
+// Thresholds for a low-click query: searched at least lowClickMin times,
+// finding results, yet opened less than lowClickRate of the time.
+const (
+	lowClickMin  = 3
+	lowClickRate = 0.2
+)
+
+// QueryCount is one row of a search report.
+type QueryCount struct {
+	Query    string `json:"query"`
+	Searches int    `json:"searches"`
+	Clicks   int    `json:"clicks"`
+	// Results is how many results the latest search found.
+	Results int `json:"results"`
+	// TopClick is the result opened most often.
+	TopClick string `json:"top_click,omitempty"`
+}
+
+// ClickRate is the share of searches that opened a result.
+func (q QueryCount) ClickRate() float64 {
+	if q.Searches == 0 {
+		return 0
+	}
+	return float64(q.Clicks) / float64(q.Searches)
+}
+
+// Report summarizes the searches against one docs SHA.
+type Report struct {
+	SHA      string       `json:"sha"`
+	Searches int          `json:"searches"`
+	Top      []QueryCount `json:"top"`
+	// ZeroResults are queries whose latest search found nothing.
+	ZeroResults []QueryCount `json:"zero_results"`
+	// LowClick are queries that find results readers don't open.
+	LowClick []QueryCount `json:"low_click"`
+}
+
+// SHACount is how many searches ran against a docs SHA.
+type SHACount struct {
+	SHA      string    `json:"sha"`
+	Searches int       `json:"searches"`
+	Last     time.Time `json:"last"`
+}
+
+// SHAs lists the docs SHAs searched against, most recently searched first.
+func SHAs(entries []Entry) []SHACount {
+	bySHA := map[string]*SHACount{}
+	for _, e := range entries {
+		row := bySHA[e.SHA]
+		if row == nil {
+			row = &SHACount{SHA: e.SHA}
+			bySHA[e.SHA] = row
+		}
+		if !e.Counted {
+			row.Searches++
+		}
+		if e.Time.After(row.Last) {
+			row.Last = e.Time
+		}
+	}
+	out := make([]SHACount, 0, len(bySHA))
+	for _, row := range bySHA {
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Last.After(out[j].Last) })
+	return out
+}
+
+// Summarize reports the entries for sha at or after since, up to limit
+// queries per list.
+func Summarize(entries []Entry, sha string, since time.Time, limit int) Report {
+	r := Report{SHA: sha}
+	byQuery := map[string]*QueryCount{}
+	clicks := map[string]map[string]int{}
+	for _, e := range entries {
+		if e.SHA != sha || e.Time.Before(since) {
+			continue
+		}
+		row := byQuery[e.Query]
+		if row == nil {
+			row = &QueryCount{Query: e.Query}
+			byQuery[e.Query] = row
+			clicks[e.Query] = map[string]int{}
+		}
+		if !e.Counted {
+			row.Searches++
+			r.Searches++
+		}
+		row.Results = e.Results
+		if e.Clicked != "" {
+			row.Clicks++
+			clicks[e.Query][e.Clicked]++
+		}
+	}
+	var all []QueryCount
+	for q, row := range byQuery {
+		best := 0
+		for path, n := range clicks[q] {
+			if n > best || (n == best && path < row.TopClick) {
+				row.TopClick, best = path, n
+			}
+		}
+		all = append(all, *row)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Searches != all[j].Searches {
+			return all[i].Searches > all[j].Searches
+		}
+		return all[i].Query < all[j].Query
+	})
+	for _, q := range all {
+		switch {
+		case q.Results == 0:
+			r.ZeroResults = append(r.ZeroResults, q)
+		case q.Searches >= lowClickMin && q.ClickRate() < lowClickRate:
+			r.LowClick = append(r.LowClick, q)
+		}
+	}
+	r.Top = head(all, limit)
+	r.ZeroResults = head(r.ZeroResults, limit)
+	r.LowClick = head(r.LowClick, limit)
+	return r
+}
+
+func head(rows []QueryCount, n int) []QueryCount {
+	if len(rows) > n {
+		return rows[:n]
+	}
+	return rows
+}
@@ -0,0 +1,54 @@
+package docsearch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPurgeDropsOldSHAs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs-searches.jsonl")
+	l := NewLog(path)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	for _, e := range []Entry{
+		{Time: now.Add(-300 * day), SHA: "old", Query: "install"},
+		{Time: now.Add(-250 * day), SHA: "old", Query: "grids"},
+		// Searched both long ago and recently: kept whole.
+		{Time: now.Add(-300 * day), SHA: "recent", Query: "appium"},
+		{Time: now.Add(-10 * day), SHA: "recent", Query: "appium"},
+		// The published tree is kept however old its searches are.
+		{Time: now.Add(-400 * day), SHA: "current", Query: "login"},
+	} {
+		if err := l.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := l.Purge(now.Add(-180*day), "current")
+	if err != nil || n != 2 {
+		t.Fatalf("Purge = %d, %v; want the 2 searches against old", n, err)
+	}
+	all, err := l.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].SHA != "recent" || all[2].SHA != "current" {
+		t.Errorf("kept %+v", all)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("rewritten log: %v, %v; want mode 0600", fi.Mode(), err)
+	}
+
+	if n, err := l.Purge(now.Add(-180*day), "current"); err != nil || n != 0 {
+		t.Errorf("second Purge = %d, %v; want 0", n, err)
+	}
+}
+
+func TestPurgeMissingLog(t *testing.T) {
+	l := NewLog(filepath.Join(t.TempDir(), "none.jsonl"))
+	if n, err := l.Purge(time.Now(), ""); err != nil || n != 0 {
+		t.Errorf("Purge = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,273 @@
+// Package feedback keeps the "Was this page helpful?" votes and comments
+// left on docs pages, and forwards comments to the docs team.
+package feedback
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Feedback is one reader's vote on a docs page, with the comment they
+// added after voting, if any.
+type Feedback struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+	// Page is the URL path of the docs page ("/docs/admin/healthpage").
+	Page string `json:"page"`
+	// SHA is the docs tree that was published when the page was rated.
+	SHA     string `json:"sha"`
+	Helpful bool   `json:"helpful"`
+	// Anchor is the heading ID of the section the comment is about ("" for
+	// the whole page).
+	Anchor  string `json:"anchor,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// New returns a vote on page with a fresh ID.
+func New(page, sha string, helpful bool) Feedback {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return Feedback{
+		ID:      hex.EncodeToString(b),
+		Time:    time.Now().UTC(),
+		Page:    page,
+		SHA:     sha,
+		Helpful: helpful,
+	}
+}
+
+// Store keeps feedback as JSON lines in a single file. A comment is
+// appended as a second line with the vote's ID; reading keeps the latest
+// line for each ID.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore returns a store backed by path; the file and its directory are
+// created on first write.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// StoreFromEnv returns the store at DOCS_FEEDBACK_FILE (default
+// ./data/docs-feedback.jsonl).
+func StoreFromEnv() *Store {
+	path := os.Getenv("DOCS_FEEDBACK_FILE")
+	if path == "" {
+		path = "./data/docs-feedback.jsonl"
+	}
+	return NewStore(path)
+}
+
+// Append records f, replacing any earlier record with its ID.
+func (s *Store) Append(f Feedback) error {
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(raw, '\n'))
+	return err
+}
+
+// Get returns the feedback with id.
+func (s *Store) Get(id string) (Feedback, bool, error) {
+	all, err := s.All()
+	if err != nil {
+		return Feedback{}, false, err
+	}
+	for _, f := range all {
+		if f.ID == id {
+			return f, true, nil
+		}
+	}
+	return Feedback{}, false, nil
+}
+
+// All returns the latest record of every vote, oldest vote first.
+func (s *Store) All() ([]Feedback, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+func (s *Store) read() ([]Feedback, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var out []Feedback
+	at := map[string]int{}
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var f Feedback
+		if err := json.Unmarshal(sc.Bytes(), &f); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.path, n, err)
+		}
+		if i, ok := at[f.ID]; ok {
+			out[i] = f
+			continue
+		}
+		at[f.ID] = len(out)
+		out = append(out, f)
+	}
+	return out, sc.Err()
+}
+
+// Purge deletes the votes given before cutoff, with their comments, and
+// reports how many it deleted.
+func (s *Store) Purge(cutoff time.Time) (int, error) {
+	return s.delete(func(f Feedback) bool { return f.Time.Before(cutoff) })
+}
+
+// Mentions reports whether f's comment contains email, the only way
+// feedback holds a reader's address.
+func (f Feedback) Mentions(email string) bool {
+	return email != "" && strings.Contains(strings.ToLower(f.Comment), strings.ToLower(email))
+}
+
+// ByEmail returns the votes whose comment mentions email.
+func (s *Store) ByEmail(email string) ([]Feedback, error) {
+	all, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+	var out []Feedback
+	for _, f := range all {
+		if f.Mentions(email) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// DeleteByEmail deletes the votes whose comment mentions email and reports
+// how many it deleted.
+func (s *Store) DeleteByEmail(email string) (int, error) {
+	return s.delete(func(f Feedback) bool { return f.Mentions(email) })
+}
+
+// delete rewrites the file without the votes drop matches, keeping one
+// line per vote.
+func (s *Store) delete(drop func(Feedback) bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	kept := all[:0]
+	for _, f := range all {
+		if !drop(f) {
+			kept = append(kept, f)
+		}
+	}
+	n := len(all) - len(kept)
+	if n == 0 {
+		return 0, nil
+	}
+	return n, s.write(kept)
+}
+
+// write replaces the file with all.
+func (s *Store) write(all []Feedback) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".docs-feedback-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, f := range all {
+		if err := enc.Encode(f); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// PageSummary tallies the votes on one page in one docs SHA.
+type PageSummary struct {
+	Page     string     `json:"page"`
+	SHA      string     `json:"sha"`
+	Helpful  int        `json:"helpful"`
+	Not      int        `json:"not_helpful"`
+	Comments []Feedback `json:"comments,omitempty"` // newest first
+}
+
+// Summarize groups feedback given at or after since by page and docs SHA,
+// pages with the most "not helpful" votes first.
+func Summarize(all []Feedback, since time.Time) []PageSummary {
+	type key struct{ page, sha string }
+	byPage := map[key]*PageSummary{}
+	for _, f := range all {
+		if f.Time.Before(since) {
+			continue
+		}
+		k := key{f.Page, f.SHA}
+		row := byPage[k]
+		if row == nil {
+			row = &PageSummary{Page: f.Page, SHA: f.SHA}
+			byPage[k] = row
+		}
+		if f.Helpful {
+			row.Helpful++
+		} else {
+			row.Not++
+		}
+		if f.Comment != "" {
+			row.Comments = append([]Feedback{f}, row.Comments...)
+		}
+	}
+	out := make([]PageSummary, 0, len(byPage))
+	for _, row := range byPage {
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Not != out[j].Not {
+			return out[i].Not > out[j].Not
+		}
+		if out[i].Page != out[j].Page {
+			return out[i].Page < out[j].Page
+		}
+		return out[i].SHA < out[j].SHA
+	})
+	return out
+}
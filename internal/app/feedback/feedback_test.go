@@ -0,0 +1,85 @@
+package feedback
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreKeepsLatestLinePerID(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "docs-feedback.jsonl"))
+	a := New("/docs/setup", "sha1", false)
+	b := New("/docs/devices", "sha1", true)
+	for _, f := range []Feedback{a, b} {
+		if err := s.Append(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.Comment, a.Anchor = "The install step fails on macOS.", "install"
+	if err := s.Append(a); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := s.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].ID != a.ID || all[1].ID != b.ID {
+		t.Fatalf("All() = %+v, want a then b, once each", all)
+	}
+	if all[0].Comment != a.Comment || all[0].Anchor != "install" {
+		t.Errorf("a = %+v, want its comment", all[0])
+	}
+	got, ok, err := s.Get(a.ID)
+	if err != nil || !ok || got.Comment != a.Comment {
+		t.Errorf("Get(a) = %+v, %v, %v", got, ok, err)
+	}
+	if _, ok, _ := s.Get("nope"); ok {
+		t.Error("Get found an unknown ID")
+	}
+}
+
+func TestStoreDeletes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs-feedback.jsonl")
+	s := NewStore(path)
+	old := New("/docs/setup", "sha1", false)
+	old.Time = time.Now().AddDate(-2, 0, 0)
+	mentions := New("/docs/setup", "sha2", false)
+	mentions.Comment = "Broken link, write to Ada@Example.com"
+	plain := New("/docs/devices", "sha2", true)
+	for _, f := range []Feedback{old, mentions, mentions, plain} {
+		if err := s.Append(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := s.Purge(time.Now().AddDate(-1, 0, 0)); err != nil || n != 1 {
+		t.Errorf("Purge = %d, %v, want 1", n, err)
+	}
+	if n, err := s.DeleteByEmail("ada@example.com"); err != nil || n != 1 {
+		t.Errorf("DeleteByEmail = %d, %v, want 1", n, err)
+	}
+	if n, err := s.DeleteByEmail("ada@example.com"); err != nil || n != 0 {
+		t.Errorf("DeleteByEmail again = %d, %v, want 0", n, err)
+	}
+	all, err := s.All()
+	if err != nil || len(all) != 1 || all[0].ID != plain.ID {
+		t.Fatalf("All() = %+v, %v, want only the plain vote", all, err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "Example.com") || strings.Count(string(raw), "\n") != 1 {
+		t.Errorf("file after deletes:\n%s", raw)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,86 @@
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Notifier passes a comment on to the docs team.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, f Feedback) error
+}
+
+// Summary is a one-line description of f for a notification, without the
+// comment.
+func Summary(f Feedback) string {
+	vote := "not helpful"
+	if f.Helpful {
+		vote = "helpful"
+	}
+	page := f.Page
+	if f.Anchor != "" {
+		page += "#" + f.Anchor
+	}
+	sha := f.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	return fmt.Sprintf("Docs feedback on %s (%s, docs %s)", page, vote, sha)
+}
+
+// Slack posts comments to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	// SiteURL makes the page a link ("https://robustest.com").
+	SiteURL string
+	Client  *http.Client
+}
+
+// SlackFromEnv returns a Slack notifier for DOCS_FEEDBACK_SLACK_WEBHOOK, or
+// nil when it is unset.
+func SlackFromEnv(siteURL string) *Slack {
+	hook := os.Getenv("DOCS_FEEDBACK_SLACK_WEBHOOK")
+	if hook == "" {
+		return nil
+	}
+	return &Slack{WebhookURL: hook, SiteURL: siteURL, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name implements Notifier.
+func (s *Slack) Name() string { return "slack" }
+
+// Notify implements Notifier.
+func (s *Slack) Notify(ctx context.Context, f Feedback) error {
+	link := strings.TrimRight(s.SiteURL, "/") + f.Page
+	if f.Anchor != "" {
+		link += "#" + f.Anchor
+	}
+	text := fmt.Sprintf("%s\n<%s>\n>%s", Summary(f), link, strings.ReplaceAll(f.Comment, "\n", "\n>"))
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
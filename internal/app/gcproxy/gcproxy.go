@@ -0,0 +1,268 @@
+// Package gcproxy forwards GoatCounter's counting beacon from our own
+// origin (/gc/count) to the self-hosted GoatCounter instance, so
+// ad-blockers and third-party filters never see it. Only what GoatCounter
+// needs to count a page view gets through: GET and POST, the parameters
+// count.js sends, no cookies, and optionally a truncated visitor address.
+// Whatever happens upstream, the browser gets a quick 204.
+package gcproxy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the instance running beside the site.
+const (
+	DefaultTarget = "http://127.0.0.1:8081"
+	DefaultHost   = "stats.robustest.com"
+)
+
+// Config configures a Proxy.
+type Config struct {
+	// Target is GoatCounter's base URL; the beacon goes to Target/count.
+	Target *url.URL
+	// Host is the virtual host GoatCounter routes the site by.
+	Host string
+	// Timeout bounds a beacon's whole round trip to GoatCounter.
+	Timeout time.Duration
+	// RateLimit is the most beacons forwarded per visitor address per
+	// minute (0 forwards all). Beacons over it are dropped.
+	RateLimit int
+	// TruncateIP zeroes the host part of visitor addresses (the last octet
+	// of IPv4, all but the first 48 bits of IPv6) before GoatCounter sees
+	// them.
+	TruncateIP bool
+}
+
+// ConfigFromEnv reads GOATCOUNTER_URL, GOATCOUNTER_HOST,
+// GOATCOUNTER_TIMEOUT (default 5s), GOATCOUNTER_RATE_LIMIT (default 60 a
+// minute) and GOATCOUNTER_TRUNCATE_IP ("true").
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Host:       DefaultHost,
+		Timeout:    5 * time.Second,
+		RateLimit:  60,
+		TruncateIP: os.Getenv("GOATCOUNTER_TRUNCATE_IP") == "true",
+	}
+	target := os.Getenv("GOATCOUNTER_URL")
+	if target == "" {
+		target = DefaultTarget
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return cfg, fmt.Errorf("invalid GOATCOUNTER_URL %q", target)
+	}
+	cfg.Target = u
+	if v := os.Getenv("GOATCOUNTER_HOST"); v != "" {
+		cfg.Host = v
+	}
+	if v := os.Getenv("GOATCOUNTER_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid GOATCOUNTER_TIMEOUT %q", v)
+		}
+		cfg.Timeout = d
+	}
+	if v := os.Getenv("GOATCOUNTER_RATE_LIMIT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("invalid GOATCOUNTER_RATE_LIMIT %q", v)
+		}
+		cfg.RateLimit = n
+	}
+	return cfg, nil
+}
+
+// params are the query parameters count.js sends: path, referrer, title,
+// event flag, screen width, bot flag, the page's query string, no-session
+// flag and a cache buster.
+var params = map[string]bool{
+	"p": true, "r": true, "t": true, "e": true, "s": true,
+	"b": true, "q": true, "ns": true, "rnd": true,
+}
+
+// campaignParam reports whether key is one of the keys of the page's query
+// string (q) GoatCounter reads campaigns from; the rest of it may hold
+// anything, tokens from emailed links included.
+func campaignParam(key string) bool {
+	return strings.HasPrefix(key, "utm_") || key == "ref" || key == "campaign" || key == "src" || key == "source"
+}
+
+// maxValue caps a forwarded parameter (titles and referrers are the long
+// ones).
+const maxValue = 2048
+
+// Proxy forwards beacons to GoatCounter.
+type Proxy struct {
+	cfg     Config
+	proxy   *httputil.ReverseProxy
+	limiter *limiter
+}
+
+// New returns a proxy for cfg.
+func New(cfg Config) *Proxy {
+	p := &Proxy{cfg: cfg}
+	if cfg.RateLimit > 0 {
+		p.limiter = &limiter{limit: cfg.RateLimit, counts: map[string]int{}}
+	}
+	p.proxy = &httputil.ReverseProxy{
+		Rewrite: p.rewrite,
+		Transport: &http.Transport{
+			DialContext:           (&net.Dialer{Timeout: cfg.Timeout, KeepAlive: 30 * time.Second}).DialContext,
+			ResponseHeaderTimeout: cfg.Timeout,
+			MaxIdleConns:          16,
+			MaxIdleConnsPerHost:   16,
+			MaxConnsPerHost:       64,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			resp.Header.Del("Set-Cookie")
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("GoatCounter: %v", err)
+			w.WriteHeader(http.StatusNoContent)
+		},
+	}
+	return p
+}
+
+// clientIPKey carries the visitor's address from Serve to rewrite.
+type clientIPKey struct{}
+
+// ServeHTTP forwards r, taking the visitor's address from RemoteAddr.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	p.Serve(w, r, host)
+}
+
+// Serve forwards r for the visitor at clientIP (behind a reverse proxy,
+// the caller knows it better than RemoteAddr does).
+func (p *Proxy) Serve(w http.ResponseWriter, r *http.Request, clientIP string) {
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if p.limiter != nil && !p.limiter.allow(clientIP) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if p.cfg.TruncateIP {
+		clientIP = truncate(clientIP)
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), p.cfg.Timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, clientIPKey{}, clientIP)
+	p.proxy.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	pr.SetURL(p.cfg.Target)
+	pr.Out.URL.Path = strings.TrimSuffix(p.cfg.Target.Path, "/") + "/count"
+	pr.Out.URL.RawPath = ""
+	pr.Out.URL.RawQuery = filterQuery(pr.In.URL.Query()).Encode()
+	pr.Out.Host = p.cfg.Host
+
+	// The beacon carries everything in the query; keep only the headers
+	// GoatCounter uses to classify the visit.
+	in := pr.Out.Header
+	pr.Out.Header = http.Header{}
+	for _, h := range []string{"User-Agent", "Accept-Language", "Referer"} {
+		if v := in.Get(h); v != "" {
+			pr.Out.Header.Set(h, v)
+		}
+	}
+	if ip, _ := pr.In.Context().Value(clientIPKey{}).(string); ip != "" {
+		pr.Out.Header.Set("X-Forwarded-For", ip)
+		pr.Out.Header.Set("X-Real-Ip", ip)
+	}
+	pr.Out.Body, pr.Out.ContentLength = http.NoBody, 0
+}
+
+// filterQuery keeps the parameters count.js sends, with the page's query
+// string (q, and any query on the path p) reduced to campaign keys.
+func filterQuery(in url.Values) url.Values {
+	out := url.Values{}
+	for k, vs := range in {
+		if !params[k] || len(vs) == 0 {
+			continue
+		}
+		v := vs[0]
+		switch k {
+		case "p":
+			v, _, _ = strings.Cut(v, "?")
+		case "q":
+			v = campaignQuery(v)
+		}
+		if len(v) > maxValue {
+			v = v[:maxValue]
+		}
+		out.Set(k, v)
+	}
+	return out
+}
+
+func campaignQuery(q string) string {
+	values, err := url.ParseQuery(strings.TrimPrefix(q, "?"))
+	if err != nil {
+		return ""
+	}
+	for k := range values {
+		if !campaignParam(strings.ToLower(k)) {
+			delete(values, k)
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+// truncate zeroes the host part of an address; anything that doesn't parse
+// is dropped.
+func truncate(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	if v4 := addr.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return addr.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// limiter counts beacons per address in fixed one-minute windows; the
+// counts are dropped wholesale when a window ends, so memory stays bounded
+// by one minute of visitors.
+type limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Time
+	counts map[string]int
+}
+
+func (l *limiter) allow(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now := time.Now(); now.Sub(l.window) >= time.Minute {
+		l.window = now
+		clear(l.counts)
+	}
+	l.counts[ip]++
+	return l.counts[ip] <= l.limit
+}
@@ -0,0 +1,250 @@
+package gcproxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// upstream is a stand-in GoatCounter that records the last beacon.
+type upstream struct {
+	*httptest.Server
+	last  *http.Request
+	calls int
+}
+
+func newUpstream(t *testing.T, h http.HandlerFunc) *upstream {
+	u := &upstream{}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.last, u.calls = r, u.calls+1
+		if h != nil {
+			h(w, r)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "goatcounter", Value: "x"})
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func testProxy(t *testing.T, target string, cfg Config) *Proxy {
+	u, err := url.Parse(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Target = u
+	if cfg.Host == "" {
+		cfg.Host = DefaultHost
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = time.Second
+	}
+	return New(cfg)
+}
+
+func beacon(p *Proxy, method, target, ip string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	r.Header.Set("User-Agent", "test-agent")
+	r.Header.Set("Cookie", "session=secret; rt_attr=x")
+	r.Header.Set("Authorization", "Basic c2VjcmV0")
+	w := httptest.NewRecorder()
+	p.Serve(w, r, ip)
+	return w
+}
+
+func TestMethods(t *testing.T) {
+	up := newUpstream(t, nil)
+	p := testProxy(t, up.URL, Config{})
+	for _, m := range []string{http.MethodGet, http.MethodPost} {
+		if w := beacon(p, m, "/gc/count?p=/", "192.0.2.1"); w.Code != http.StatusOK {
+			t.Errorf("%s: status %d, want 200", m, w.Code)
+		}
+	}
+	for _, m := range []string{http.MethodPut, http.MethodDelete, http.MethodPatch, http.MethodOptions} {
+		w := beacon(p, m, "/gc/count?p=/", "192.0.2.1")
+		if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, POST" {
+			t.Errorf("%s: status %d Allow %q, want 405 GET, POST", m, w.Code, w.Header().Get("Allow"))
+		}
+	}
+	if up.calls != 2 {
+		t.Errorf("upstream saw %d beacons, want 2", up.calls)
+	}
+}
+
+func TestParamAllowlist(t *testing.T) {
+	up := newUpstream(t, nil)
+	p := testProxy(t, up.URL+"/base/", Config{})
+	q := url.Values{
+		"p":     {"/pricing?token=abc"},
+		"t":     {"Pricing"},
+		"q":     {"?utm_source=mail&token=abc&ref=hn"},
+		"rnd":   {"123"},
+		"email": {"ada@example.com"},
+		"token": {"abc"},
+	}
+	beacon(p, http.MethodGet, "/gc/count?"+q.Encode(), "192.0.2.1")
+
+	if up.last.URL.Path != "/base/count" {
+		t.Errorf("path = %q, want /base/count", up.last.URL.Path)
+	}
+	if up.last.Host != DefaultHost {
+		t.Errorf("Host = %q, want %q", up.last.Host, DefaultHost)
+	}
+	got := up.last.URL.Query()
+	want := url.Values{
+		"p":   {"/pricing"},
+		"t":   {"Pricing"},
+		"q":   {"?ref=hn&utm_source=mail"},
+		"rnd": {"123"},
+	}
+	if got.Encode() != want.Encode() {
+		t.Errorf("forwarded query = %q, want %q", got.Encode(), want.Encode())
+	}
+}
+
+func TestLongValuesAreCapped(t *testing.T) {
+	up := newUpstream(t, nil)
+	p := testProxy(t, up.URL, Config{})
+	long := make([]byte, maxValue+100)
+	for i := range long {
+		long[i] = 'a'
+	}
+	beacon(p, http.MethodGet, "/gc/count?t="+string(long), "192.0.2.1")
+	if n := len(up.last.URL.Query().Get("t")); n != maxValue {
+		t.Errorf("title forwarded with %d bytes, want %d", n, maxValue)
+	}
+}
+
+func TestCookiesStripped(t *testing.T) {
+	up := newUpstream(t, nil)
+	p := testProxy(t, up.URL, Config{})
+	w := beacon(p, http.MethodGet, "/gc/count?p=/", "192.0.2.1")
+
+	if c := up.last.Header.Get("Cookie"); c != "" {
+		t.Errorf("upstream got Cookie %q", c)
+	}
+	if a := up.last.Header.Get("Authorization"); a != "" {
+		t.Errorf("upstream got Authorization %q", a)
+	}
+	if ua := up.last.Header.Get("User-Agent"); ua != "test-agent" {
+		t.Errorf("upstream User-Agent = %q, want test-agent", ua)
+	}
+	if sc := w.Header().Get("Set-Cookie"); sc != "" {
+		t.Errorf("browser got Set-Cookie %q", sc)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", cc)
+	}
+}
+
+func TestTruncateIP(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"192.0.2.123", "192.0.2.0"},
+		{"::ffff:192.0.2.123", "192.0.2.0"},
+		{"2001:db8:abcd:1234:5678::1", "2001:db8:abcd::"},
+		{"not-an-ip", ""},
+		{"", ""},
+	} {
+		if got := truncate(tc.in); got != tc.want {
+			t.Errorf("truncate(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	up := newUpstream(t, nil)
+	for _, tc := range []struct {
+		truncate bool
+		want     string
+	}{{false, "192.0.2.123"}, {true, "192.0.2.0"}} {
+		p := testProxy(t, up.URL, Config{TruncateIP: tc.truncate})
+		beacon(p, http.MethodGet, "/gc/count?p=/", "192.0.2.123")
+		if got := up.last.Header.Get("X-Forwarded-For"); got != tc.want {
+			t.Errorf("TruncateIP=%v: X-Forwarded-For = %q, want %q", tc.truncate, got, tc.want)
+		}
+	}
+}
+
+func TestServeHTTPUsesRemoteAddr(t *testing.T) {
+	up := newUpstream(t, nil)
+	p := testProxy(t, up.URL, Config{})
+	r := httptest.NewRequest(http.MethodGet, "/gc/count?p=/", nil)
+	r.RemoteAddr = "198.51.100.7:4567"
+	p.ServeHTTP(httptest.NewRecorder(), r)
+	if got := up.last.Header.Get("X-Forwarded-For"); got != "198.51.100.7" {
+		t.Errorf("X-Forwarded-For = %q, want 198.51.100.7", got)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	up := newUpstream(t, nil)
+	p := testProxy(t, up.URL, Config{RateLimit: 3})
+	for i := 0; i < 5; i++ {
+		w := beacon(p, http.MethodGet, "/gc/count?p=/", "192.0.2.1")
+		if i >= 3 && w.Code != http.StatusNoContent {
+			t.Errorf("beacon %d over the limit: status %d, want 204", i+1, w.Code)
+		}
+	}
+	beacon(p, http.MethodGet, "/gc/count?p=/", "192.0.2.2")
+	if up.calls != 4 {
+		t.Errorf("upstream saw %d beacons, want 3 from the limited visitor and 1 from another", up.calls)
+	}
+}
+
+func TestUpstreamDown(t *testing.T) {
+	up := newUpstream(t, nil)
+	target := up.URL
+	up.Close()
+	p := testProxy(t, target, Config{})
+	if w := beacon(p, http.MethodGet, "/gc/count?p=/", "192.0.2.1"); w.Code != http.StatusNoContent {
+		t.Errorf("status %d with GoatCounter down, want 204", w.Code)
+	}
+}
+
+func TestUpstreamTimeout(t *testing.T) {
+	release := make(chan struct{})
+	up := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+	p := testProxy(t, up.URL, Config{Timeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	w := beacon(p, http.MethodGet, "/gc/count?p=/", "192.0.2.1")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status %d with GoatCounter hanging, want 204", w.Code)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("beacon took %v; the timeout did not cut it short", d)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("GOATCOUNTER_URL", "http://gc.internal:9000")
+	t.Setenv("GOATCOUNTER_RATE_LIMIT", "10")
+	t.Setenv("GOATCOUNTER_TRUNCATE_IP", "true")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Target.Host != "gc.internal:9000" || cfg.RateLimit != 10 || !cfg.TruncateIP || cfg.Host != DefaultHost {
+		t.Errorf("ConfigFromEnv = %+v", cfg)
+	}
+
+	for k, v := range map[string]string{
+		"GOATCOUNTER_URL":        "not a url",
+		"GOATCOUNTER_TIMEOUT":    "soon",
+		"GOATCOUNTER_RATE_LIMIT": "-1",
+	} {
+		t.Run(k, func(t *testing.T) {
+			t.Setenv(k, v)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("%s=%q accepted", k, v)
+			}
+		})
+	}
+}
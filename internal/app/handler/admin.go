@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/leads"
+	"github.com/izinga/robustest-web/internal/app/views/pages"
+)
+
+// AdminAuth guards /admin with HTTP basic auth from ADMIN_USER (default
+// "admin") and ADMIN_PASSWORD. Without a password the admin area does not
+// exist: every request gets the regular 404.
+func AdminAuth() gin.HandlerFunc {
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		return func(c *gin.Context) {
+			NotFoundPage(c)
+			c.Abort()
+		}
+	}
+	user := os.Getenv("ADMIN_USER")
+	if user == "" {
+		user = "admin"
+	}
+	basic := gin.BasicAuthForRealm(gin.Accounts{user: password}, "RobusTest admin")
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.Header("X-Robots-Tag", "noindex")
+		basic(c)
+	}
+}
+
+// AdminIndex lists the available reports.
+func AdminIndex(c *gin.Context) {
+	renderPage(c, "admin", func() error {
+		return pages.AdminIndex().Render(c.Request.Context(), c.Writer)
+	})
+}
+
+// reportDays reads the ?days= window for reports (default 90).
+func reportDays(c *gin.Context) int {
+	days, err := strconv.Atoi(c.Query("days"))
+	if err != nil || days <= 0 {
+		days = 90
+	}
+	return days
+}
+
+// LeadSourcesReport shows how many leads each page on the site produced.
+func LeadSourcesReport(c *gin.Context) {
+	all, err := leadStore.All()
+	if err != nil {
+		log.Printf("Error reading leads: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	days := reportDays(c)
+	rows := leads.SourceReport(all, time.Now().AddDate(0, 0, -days))
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{"days": days, "sources": rows})
+		return
+	}
+	renderPage(c, "lead sources", func() error {
+		return pages.AdminLeadSources(rows, days).Render(c.Request.Context(), c.Writer)
+	})
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/analytics"
+	"github.com/izinga/robustest-web/internal/app/views/pages"
+)
+
+// Analytics backends (ANALYTICS_BACKEND) answering the /gc/count beacon.
+const (
+	analyticsGoatCounter = "goatcounter" // proxied to GoatCounter (gcproxy)
+	analyticsBuiltin     = "builtin"     // counted here (package analytics)
+)
+
+var analyticsBackend = analyticsGoatCounter
+
+// analyticsStore is the built-in collector's database (nil unless the
+// backend is builtin and the database opened).
+var analyticsStore *analytics.Store
+
+// beaconRateLimiter caps beacons counted per visitor address.
+var beaconRateLimiter = &rateLimiter{
+	requests: make(map[string][]time.Time),
+	limit:    60,
+	window:   time.Minute,
+}
+
+// InitAnalytics reads ANALYTICS_BACKEND: "goatcounter" (default) to proxy
+// beacons to GoatCounter beside Plausible, or "builtin" to count them in
+// ANALYTICS_DB instead of both. Call it before InitRetention and anything
+// that renders pages.
+func InitAnalytics() {
+	switch v := os.Getenv("ANALYTICS_BACKEND"); v {
+	case "", analyticsGoatCounter:
+		analyticsBackend = analyticsGoatCounter
+		return
+	case analyticsBuiltin:
+		analyticsBackend = analyticsBuiltin
+		analytics.UseBuiltin(true)
+	default:
+		log.Printf("Warning: invalid ANALYTICS_BACKEND %q, using %s", v, analyticsGoatCounter)
+		analyticsBackend = analyticsGoatCounter
+		return
+	}
+	store, err := analytics.OpenFromEnv()
+	if err != nil {
+		log.Printf("Warning: analytics disabled: %v", err)
+		return
+	}
+	analyticsStore = store
+}
+
+// BuiltinAnalytics reports whether /gc/count is answered by CollectBeacon
+// rather than proxied to GoatCounter.
+func BuiltinAnalytics() bool {
+	return analyticsBackend == analyticsBuiltin
+}
+
+// CloseAnalytics closes the built-in collector's database.
+func CloseAnalytics() {
+	if analyticsStore != nil {
+		if err := analyticsStore.Close(); err != nil {
+			log.Printf("Error closing analytics: %v", err)
+		}
+	}
+}
+
+// CollectBeacon counts a count.js beacon: a page view, or with e=true an
+// event named by p. Bots (count.js's b), visitors over the rate limit and
+// malformed beacons are dropped; the answer is always 204, the browser has
+// no use for anything else.
+func CollectBeacon(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodPost {
+		c.Header("Allow", "GET, POST")
+		c.Status(http.StatusMethodNotAllowed)
+		return
+	}
+	c.Status(http.StatusNoContent)
+	if analyticsStore == nil || !beaconRateLimiter.isAllowed(c.ClientIP()) {
+		return
+	}
+	q := c.Request.URL.Query()
+	path, _, _ := strings.Cut(q.Get("p"), "?")
+	if path == "" || (q.Get("b") != "" && q.Get("b") != "0") {
+		return
+	}
+	now := time.Now()
+	visitor, err := analyticsStore.Visitor(c.ClientIP(), c.Request.UserAgent(), now)
+	if err != nil {
+		log.Printf("Error counting beacon: %v", err)
+		return
+	}
+	hit := analytics.Hit{
+		Time:    now,
+		Path:    clip(path),
+		Event:   q.Get("e") == "true",
+		Title:   clip(q.Get("t")),
+		Visitor: visitor,
+	}
+	if hit.Event {
+		// count.js sends the event name as the path; the page it happened
+		// on is the beacon's own referrer.
+		if u, err := url.Parse(c.Request.Referer()); err == nil && u.Host == c.Request.Host {
+			hit.Page = clip(u.Path)
+		}
+	} else {
+		hit.Referrer = clip(beaconReferrer(q, c.Request.Host))
+	}
+	if err := analyticsStore.Record(hit); err != nil {
+		log.Printf("Error counting beacon: %v", err)
+	}
+}
+
+// beaconReferrer returns where a page view came from: the page's
+// utm_source, else the host of the referrer count.js reports, unless that
+// is this site.
+func beaconReferrer(q url.Values, host string) string {
+	if page, err := url.ParseQuery(strings.TrimPrefix(q.Get("q"), "?")); err == nil {
+		if src := page.Get("utm_source"); src != "" {
+			return src
+		}
+	}
+	u, err := url.Parse(q.Get("r"))
+	if err != nil || u.Host == "" || u.Host == host {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// AnalyticsReport shows the built-in collector's dashboard: top pages,
+// referrers, CTA clicks, events and docs searches.
+func AnalyticsReport(c *gin.Context) {
+	days := reportDays(c)
+	var report *analytics.Report
+	if analyticsStore != nil {
+		r, err := analyticsStore.Report(time.Now().AddDate(0, 0, -days))
+		if err != nil {
+			log.Printf("Error reading analytics: %v", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		report = &r
+	}
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{"days": days, "report": report})
+		return
+	}
+	renderPage(c, "analytics", func() error {
+		return pages.AdminAnalytics(report, days).Render(c.Request.Context(), c.Writer)
+	})
+}
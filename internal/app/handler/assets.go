@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/assets"
+	"github.com/izinga/robustest-web/internal/app/compress"
+)
+
+// publicFS is the public/ tree: /assets and robots.txt.
+var publicFS fs.FS
+
+// liveAssets is set when publicFS is a directory on disk, whose files may
+// change under the running server.
+var liveAssets bool
+
+// assetManifest fingerprints the files under /assets (empty when live).
+var assetManifest = &assets.Manifest{}
+
+// Cache lifetimes for /assets: fingerprinted names never change content;
+// plain names (and fingerprints of older builds) may with the next deploy,
+// and files served live from disk may at any time.
+const (
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	assetCacheControl     = "public, max-age=300"
+	liveCacheControl      = "no-cache"
+)
+
+// assetFile is an asset held in memory.
+type assetFile struct {
+	compress.Variants // only Identity for types not worth compressing
+	contentType       string
+	modTime           time.Time
+	etag              string // of the identity body; variants add their coding
+}
+
+// assetFiles maps asset paths (e.g. "css/app.css") to their contents. It
+// is built once by InitAssets and only read afterwards.
+var assetFiles map[string]*assetFile
+
+// InitAssets chooses the public/ tree to serve: the directory dir when it
+// is set (ASSETS_PATH, for development) and exists, the embedded copy
+// otherwise. A directory is served live, as it is on disk. The embedded
+// tree is loaded into memory: every file is fingerprinted for assets.URL,
+// and stylesheets, scripts and other text files are encoded with gzip and
+// brotli up front, so they are never compressed per request.
+func InitAssets(dir string, embedded fs.FS) {
+	if dir != "" {
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			publicFS, liveAssets = os.DirFS(dir), true
+			log.Printf("Assets: serving %s live from disk", dir)
+			return
+		}
+		log.Printf("Warning: ASSETS_PATH %s is not a directory; serving the embedded assets", dir)
+	}
+	publicFS = embedded
+	fsys, err := fs.Sub(embedded, "assets")
+	if err == nil {
+		assetManifest, err = assets.Build(fsys)
+	}
+	if err != nil {
+		log.Printf("Warning: assets manifest: %v", err)
+	}
+	assets.Use(assetManifest)
+
+	assetFiles = map[string]*assetFile{}
+	encoded, size := 0, 0
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		ctype := mime.TypeByExtension(path.Ext(name))
+		sum := sha256.Sum256(body)
+		a := &assetFile{
+			Variants:    compress.Variants{Identity: body},
+			contentType: ctype,
+			modTime:     info.ModTime(),
+			etag:        hex.EncodeToString(sum[:16]),
+		}
+		if compress.Compressible(ctype) {
+			a.Variants = compress.Precompress(body)
+			encoded++
+		}
+		assetFiles[name] = a
+		size += len(a.Identity) + len(a.Gzip) + len(a.Brotli)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Warning: assets: %v", err)
+	}
+	log.Printf("Assets: %d embedded files fingerprinted, %d precompressed (%d KiB in memory)", assetManifest.Len(), encoded, size/1024)
+}
+
+// Assets serves a file under /assets by its plain or fingerprinted name,
+// in the client's preferred encoding when it was precompressed.
+func Assets(c *gin.Context) {
+	name := strings.TrimPrefix(path.Clean(c.Param("filepath")), "/")
+	if liveAssets {
+		serveLive(c, "assets/"+name)
+		return
+	}
+	name, current := assetManifest.Resolve(name)
+	a, ok := assetFiles[name]
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if current {
+		c.Header("Cache-Control", immutableCacheControl)
+	} else {
+		c.Header("Cache-Control", assetCacheControl)
+	}
+	body, coding := a.Pick(c.GetHeader("Accept-Encoding"))
+	h := c.Writer.Header()
+	if a.Gzip != nil || a.Brotli != nil {
+		compress.AddVary(h)
+	}
+	if a.contentType != "" {
+		h.Set("Content-Type", a.contentType)
+	}
+	etag := a.etag
+	if coding != "" {
+		h.Set("Content-Encoding", coding)
+		etag += "-" + coding
+	}
+	h.Set("ETag", `"`+etag+`"`)
+	http.ServeContent(c.Writer, c.Request, name, a.modTime, bytes.NewReader(body))
+}
+
+// RobotsTxt serves /robots.txt from the public/ tree.
+func RobotsTxt(c *gin.Context) {
+	if liveAssets {
+		serveLive(c, "robots.txt")
+		return
+	}
+	c.FileFromFS("robots.txt", http.FS(publicFS))
+}
+
+// serveLive serves the file at name in publicFS as it currently is on disk.
+func serveLive(c *gin.Context, name string) {
+	if info, err := fs.Stat(publicFS, name); err != nil || info.IsDir() {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.Header("Cache-Control", liveCacheControl)
+	c.FileFromFS(name, http.FS(publicFS))
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/leads"
+)
+
+// attributionCookie holds the visitor's first-party marketing attribution:
+// campaign parameters, external referrer, and landing page of the visit that
+// brought them here. It is read back when they submit the contact form.
+const attributionCookie = "rt_attr"
+
+// attributionMaxAge keeps a touch attributable for a typical B2B evaluation.
+const attributionMaxAge = 90 * 24 * 60 * 60
+
+// maxAttributionValue caps each attribution value; they come from URLs and
+// headers the visitor controls.
+const maxAttributionValue = 200
+
+var utmParams = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+
+// CaptureAttribution records the campaign of a visit that lands through a
+// campaign link (utm_* parameters) in a first-party cookie, so the latest
+// campaign wins. Other first visits are recorded by RecordAttribution,
+// called from the page's script: a page response that set a cookie could
+// not be cached by a CDN.
+func CaptureAttribution() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet && isPageView(c.Request.URL.Path) && hasUTM(c.Request.URL.Query()) {
+			setAttribution(c, c.Request.URL, c.Request.Referer())
+		}
+		c.Next()
+	}
+}
+
+// RecordAttribution (POST /api/attribution) records the first touch of a
+// visitor without an attribution cookie: the landing page and external
+// referrer app.js and docs.js report for the first page of a session. A
+// visitor who already has the cookie keeps it. It always answers 204.
+func RecordAttribution(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+	if _, err := c.Cookie(attributionCookie); err == nil {
+		return
+	}
+	var req struct {
+		Referrer string `json:"referrer"`
+		Landing  string `json:"landing"`
+	}
+	if err := json.NewDecoder(io.LimitReader(c.Request.Body, 4<<10)).Decode(&req); err != nil {
+		return
+	}
+	landing, err := url.Parse(req.Landing)
+	if err != nil || landing.IsAbs() || landing.Host != "" || !strings.HasPrefix(landing.Path, "/") || !isPageView(landing.Path) {
+		return
+	}
+	setAttribution(c, landing, req.Referrer)
+}
+
+// hasUTM reports whether q carries any campaign parameter.
+func hasUTM(q url.Values) bool {
+	for _, p := range utmParams {
+		if q.Get(p) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// setAttribution sets the attribution cookie for a visit that landed on
+// landing from referrer.
+func setAttribution(c *gin.Context, landing *url.URL, referrer string) {
+	q := landing.Query()
+	a := leads.Attribution{
+		UTMSource:   clip(q.Get("utm_source")),
+		UTMMedium:   clip(q.Get("utm_medium")),
+		UTMCampaign: clip(q.Get("utm_campaign")),
+		UTMTerm:     clip(q.Get("utm_term")),
+		UTMContent:  clip(q.Get("utm_content")),
+		Referrer:    externalReferrer(referrer, c.Request.Host),
+		LandingPage: clip(landing.Path),
+	}
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(attributionCookie, base64.RawURLEncoding.EncodeToString(raw),
+		attributionMaxAge, "/", "", c.Request.TLS != nil, true)
+}
+
+// isPageView reports whether a GET is a page a visitor can land on, as
+// opposed to an asset, API call, or machine-readable file.
+func isPageView(p string) bool {
+	for _, prefix := range []string{"/assets/", "/api/", "/gc/", "/admin", "/health", "/docs/assets/"} {
+		if strings.HasPrefix(p, prefix) {
+			return false
+		}
+	}
+	return path.Ext(p) == ""
+}
+
+// externalReferrer returns the referrer's origin and path when it points
+// to a site other than host; internal navigation and query strings are
+// dropped.
+func externalReferrer(referrer, host string) string {
+	ref, err := url.Parse(referrer)
+	if err != nil || ref.Host == "" || ref.Host == host || (ref.Scheme != "http" && ref.Scheme != "https") {
+		return ""
+	}
+	return clip(ref.Scheme + "://" + ref.Host + ref.Path)
+}
+
+// readAttribution decodes the attribution cookie, if the visitor has one.
+func readAttribution(c *gin.Context) leads.Attribution {
+	var a leads.Attribution
+	v, err := c.Cookie(attributionCookie)
+	if err != nil {
+		return a
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return a
+	}
+	_ = json.Unmarshal(raw, &a)
+	return a
+}
+
+// sourcePageFromReferer returns the same-site page a visitor came from
+// before opening /contact (e.g. /platform/tv-testing), or "".
+func sourcePageFromReferer(c *gin.Context) string {
+	ref, err := url.Parse(c.Request.Referer())
+	if err != nil || ref.Host != c.Request.Host || ref.Path == "/contact" {
+		return ""
+	}
+	return clip(ref.Path)
+}
+
+// mergeAttribution fills empty fields of a with the values from fallback,
+// then clips everything to a sane length.
+func mergeAttribution(a, fallback leads.Attribution) leads.Attribution {
+	pick := func(v, f string) string {
+		if v == "" {
+			v = f
+		}
+		return clip(strings.TrimSpace(v))
+	}
+	return leads.Attribution{
+		UTMSource:   pick(a.UTMSource, fallback.UTMSource),
+		UTMMedium:   pick(a.UTMMedium, fallback.UTMMedium),
+		UTMCampaign: pick(a.UTMCampaign, fallback.UTMCampaign),
+		UTMTerm:     pick(a.UTMTerm, fallback.UTMTerm),
+		UTMContent:  pick(a.UTMContent, fallback.UTMContent),
+		Referrer:    pick(a.Referrer, fallback.Referrer),
+		LandingPage: pick(a.LandingPage, fallback.LandingPage),
+		SourcePage:  pick(a.SourcePage, fallback.SourcePage),
+	}
+}
+
+// clip cuts s to maxAttributionValue bytes on a rune boundary, so the
+// cookie and the stored lead hold valid UTF-8.
+func clip(s string) string {
+	if len(s) <= maxAttributionValue {
+		return s
+	}
+	n := maxAttributionValue
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestClipCutsOnRunes(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"short", "short"},
+		{strings.Repeat("a", maxAttributionValue), strings.Repeat("a", maxAttributionValue)},
+		// The cut at maxAttributionValue bytes lands inside the first é.
+		{strings.Repeat("a", maxAttributionValue-1) + "é", strings.Repeat("a", maxAttributionValue-1)},
+		// Three-byte runes: 66 fit, the 67th would straddle the limit.
+		{strings.Repeat("日", 100), strings.Repeat("日", maxAttributionValue/3)},
+	} {
+		got := clip(tc.in)
+		if !utf8.ValidString(got) || got != tc.want {
+			t.Errorf("clip(%d bytes) = %q (%d bytes), want %d bytes", len(tc.in), got, len(got), len(tc.want))
+		}
+	}
+}
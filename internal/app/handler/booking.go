@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/booking"
+	"github.com/izinga/robustest-web/internal/app/redact"
+	"github.com/izinga/robustest-web/internal/app/tokens"
+	"github.com/izinga/robustest-web/internal/app/views/components"
+	"github.com/izinga/robustest-web/internal/app/views/pages"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// demoScheduler books demo slots. It is nil when no booking config exists;
+// the contact form then has no slot picker and the booking pages 404.
+var demoScheduler *booking.Scheduler
+
+// InitBooking loads the sales engineers' availability from BOOKING_CONFIG
+// (see booking.Config) and the reservations from BOOKINGS_FILE.
+func InitBooking() {
+	path := booking.ConfigPathFromEnv()
+	cfg, err := booking.LoadConfig(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Demo booking disabled: %s not found", path)
+		return
+	}
+	if err != nil {
+		log.Printf("Demo booking disabled: %v", err)
+		return
+	}
+	demoScheduler = booking.NewScheduler(cfg, booking.StoreFromEnv())
+	log.Printf("Demo booking enabled for %d engineers", len(cfg.Engineers))
+}
+
+// Token purposes for the links in booking emails.
+const (
+	purposeBookingCancel     = "booking-cancel"
+	purposeBookingReschedule = "booking-reschedule"
+)
+
+// maxSlotDays caps how many days the slot picker lists.
+const maxSlotDays = 10
+
+// visitorLocation resolves the visitor's IANA time zone, falling back to UTC.
+func visitorLocation(name string) *time.Location {
+	if name != "" && len(name) <= 64 {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// formatSlot renders a demo time for people, e.g.
+// "Tue 20 Oct 2026, 14:30 (Europe/Berlin)".
+func formatSlot(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("Mon 2 Jan 2006, 15:04") + " (" + loc.String() + ")"
+}
+
+// slotDays groups open slots by day in the visitor's time zone.
+func slotDays(slots []booking.Slot, loc *time.Location) []components.SlotDay {
+	var days []components.SlotDay
+	for _, s := range slots {
+		local := s.Start.In(loc)
+		label := local.Format("Mon 2 Jan")
+		if len(days) == 0 || days[len(days)-1].Label != label {
+			if len(days) == maxSlotDays {
+				break
+			}
+			days = append(days, components.SlotDay{Label: label})
+		}
+		day := &days[len(days)-1]
+		day.Slots = append(day.Slots, components.SlotOption{
+			Value: s.Start.UTC().Format(time.RFC3339),
+			Label: local.Format("15:04"),
+		})
+	}
+	return days
+}
+
+// ContactSlots renders the demo slot picker for the contact form and the
+// reschedule page, in the time zone the browser reports (?tz=).
+func ContactSlots(c *gin.Context) {
+	if demoScheduler == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	slots, err := demoScheduler.Slots()
+	if err != nil {
+		log.Printf("Error listing demo slots: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	loc := visitorLocation(c.Query("tz"))
+	minutes := int(demoScheduler.Duration() / time.Minute)
+	optional := c.Query("reschedule") == ""
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	if err := components.SlotPicker(slotDays(slots, loc), c.Query("slot"), loc.String(), minutes, optional).Render(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("Error rendering slot picker: %v", err)
+	}
+}
+
+// validateSlot checks the requested demo time, if any. Whether it is still
+// free is only known when it is reserved.
+func (req *ContactFormRequest) validateSlot() map[string]string {
+	if req.Slot == "" {
+		return nil
+	}
+	if demoScheduler == nil || req.LeadType == "partner" {
+		return map[string]string{"slot": "Demo booking is not available; we'll find a time by email."}
+	}
+	if _, err := time.Parse(time.RFC3339, req.Slot); err != nil {
+		return map[string]string{"slot": "Pick one of the listed times."}
+	}
+	return nil
+}
+
+// bookDemo reserves the requested slot for the lead.
+func bookDemo(req ContactFormRequest, leadID string) (booking.Booking, error) {
+	start, err := time.Parse(time.RFC3339, req.Slot)
+	if err != nil {
+		return booking.Booking{}, err
+	}
+	return demoScheduler.Book(booking.Booking{
+		Start:    start,
+		Name:     req.Name,
+		Email:    req.Email,
+		Company:  req.Company,
+		TimeZone: visitorLocation(req.TimeZone).String(),
+		LeadID:   leadID,
+	})
+}
+
+// demoSummary describes a booked demo for the team notification, or "".
+func (req ContactFormRequest) demoSummary() string {
+	if req.booked == nil {
+		return ""
+	}
+	who := req.booked.Engineer
+	if e, ok := demoScheduler.Engineer(who); ok {
+		who = e.Name
+	}
+	return formatSlot(req.booked.Start, time.UTC) + " with " + who
+}
+
+// contactBooked answers an accepted submission that also booked a demo.
+func contactBooked(c *gin.Context, b booking.Booking) {
+	if wantsJSON(c) {
+		c.JSON(http.StatusOK, gin.H{"status": "received", "booking": gin.H{
+			"id": b.ID, "start": b.Start, "end": b.End,
+		}})
+		return
+	}
+	who := ""
+	if e, ok := demoScheduler.Engineer(b.Engineer); ok {
+		who = e.Name
+	}
+	c.Status(http.StatusOK)
+	if err := components.DemoBooked(formatSlot(b.Start, visitorLocation(b.TimeZone)), who).Render(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("Error rendering booking response: %v", err)
+	}
+}
+
+// bookingLinks returns the signed cancel and reschedule URLs for b. They
+// stop working when the demo is over.
+func bookingLinks(b booking.Booking) (cancel, reschedule string) {
+	cancel = siteURL("/booking/cancel?t=" + url.QueryEscape(linkSigner.Sign(purposeBookingCancel, b.ID, b.End)))
+	reschedule = siteURL("/booking/reschedule?t=" + url.QueryEscape(linkSigner.Sign(purposeBookingReschedule, b.ID, b.End)))
+	return cancel, reschedule
+}
+
+// sendBookingInvites emails the booking's ICS invite (or cancellation) to
+// the visitor, with cancel and reschedule links, and to the engineer. The
+// visitor's email repeats nothing from the form, not even the name in the
+// To header or the invite, since the address is not confirmed yet;
+// verifyURL, when set, is the link that confirms it.
+func sendBookingInvites(b booking.Booking, subject, verifyURL string) error {
+	e, ok := demoScheduler.Engineer(b.Engineer)
+	if !ok {
+		return fmt.Errorf("unknown engineer %q", b.Engineer)
+	}
+	visitorWhen := formatSlot(b.Start, visitorLocation(b.TimeZone))
+	engineerWhen := formatSlot(b.Start, e.Location())
+	cancelURL, rescheduleURL := bookingLinks(b)
+
+	var status string
+	var visitorLines, engineerLines []string
+	if b.Active() {
+		status = "booked"
+		visitorLines = []string{
+			fmt.Sprintf("Your live RobusTest demo is booked for %s with %s.", visitorWhen, e.Name),
+			"The calendar invite is attached; the video call link follows from your engineer before the demo.",
+		}
+		engineerLines = []string{
+			fmt.Sprintf("Demo with %s <%s> on %s.", b.Name, b.Email, engineerWhen),
+		}
+		if b.Company != "" {
+			engineerLines = append(engineerLines, "Company: "+b.Company)
+		}
+	} else {
+		status = "canceled"
+		visitorLines = []string{fmt.Sprintf("Your RobusTest demo on %s is canceled. You can book a new time at any point.", visitorWhen)}
+		engineerLines = []string{fmt.Sprintf("%s <%s> canceled the demo on %s.", b.Name, b.Email, engineerWhen)}
+	}
+	description := fmt.Sprintf("Live RobusTest demo with %s against a real device lab.", e.Name)
+	ics := booking.ICS(b, e, description)
+
+	var links [][2]string
+	if verifyURL != "" {
+		links = append(links, [2]string{"Confirm your email address", verifyURL})
+	}
+	if b.Active() {
+		links = append(links, [2]string{"Reschedule", rescheduleURL}, [2]string{"Cancel", cancelURL})
+	} else {
+		links = append(links, [2]string{"Book a new time", siteURL("/contact")})
+	}
+	htmlBody, textBody := buildNoticeEmail("", visitorLines, links)
+	if err := sendInvite(mail.NewEmail("", b.Email), subject, htmlBody, textBody, ics, b.Active()); err != nil {
+		return fmt.Errorf("visitor invite: %w", err)
+	}
+	htmlBody, textBody = buildNoticeEmail(e.Name, engineerLines, nil)
+	if err := sendInvite(mail.NewEmail(e.Name, e.Email), "Demo "+status+": "+b.Name, htmlBody, textBody, ics, b.Active()); err != nil {
+		return fmt.Errorf("engineer invite: %w", err)
+	}
+	return nil
+}
+
+// sendInvite sends an email with an ICS attachment through sendMail.
+func sendInvite(to *mail.Email, subject, htmlContent, textContent string, ics []byte, active bool) error {
+	method := "REQUEST"
+	if !active {
+		method = "CANCEL"
+	}
+	from := mail.NewEmail("RobusTest", contactFromEmail())
+	message := mail.NewSingleEmail(from, subject, to, textContent, htmlContent)
+	invite := mail.NewAttachment()
+	invite.SetContent(base64.StdEncoding.EncodeToString(ics))
+	invite.SetType("text/calendar; charset=utf-8; method=" + method)
+	invite.SetFilename("invite.ics")
+	invite.SetDisposition("attachment")
+	message.AddAttachment(invite)
+
+	status, err := sendMail(message)
+	if err != nil {
+		return err
+	}
+	log.Printf("Booking email sent to %s, status: %d", redact.Email(to.Address), status)
+	return nil
+}
+
+// bookingFromToken resolves the booking a cancel or reschedule link points
+// to. On failure it renders the matching error page and returns false.
+func bookingFromToken(c *gin.Context, purpose, token string) (booking.Booking, bool) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	if demoScheduler == nil {
+		NotFoundPage(c)
+		return booking.Booking{}, false
+	}
+	id, err := linkSigner.Verify(purpose, token)
+	var b booking.Booking
+	if err == nil {
+		b, err = demoScheduler.Get(id)
+	}
+	switch {
+	case errors.Is(err, tokens.ErrExpired):
+		renderBookingPage(c, http.StatusGone, pages.BookingView{State: "expired"})
+		return b, false
+	case err != nil:
+		if !errors.Is(err, tokens.ErrInvalid) && !errors.Is(err, booking.ErrNotFound) {
+			log.Printf("Error loading booking: %v", err)
+		}
+		renderBookingPage(c, http.StatusBadRequest, pages.BookingView{State: "invalid"})
+		return b, false
+	case !b.Active():
+		renderBookingPage(c, http.StatusOK, bookingView(b, "canceled", ""))
+		return b, false
+	}
+	return b, true
+}
+
+func bookingView(b booking.Booking, state, token string) pages.BookingView {
+	v := pages.BookingView{
+		State: state,
+		Token: token,
+		When:  formatSlot(b.Start, visitorLocation(b.TimeZone)),
+	}
+	if e, ok := demoScheduler.Engineer(b.Engineer); ok {
+		v.Engineer = e.Name
+	}
+	return v
+}
+
+func renderBookingPage(c *gin.Context, status int, v pages.BookingView) {
+	c.Status(status)
+	renderPage(c, "booking", func() error {
+		return pages.BookingPage(v).Render(c.Request.Context(), c.Writer)
+	})
+}
+
+// BookingCancelPage asks the visitor to confirm canceling their demo.
+func BookingCancelPage(c *gin.Context) {
+	token := c.Query("t")
+	if b, ok := bookingFromToken(c, purposeBookingCancel, token); ok {
+		renderBookingPage(c, http.StatusOK, bookingView(b, "confirm-cancel", token))
+	}
+}
+
+// BookingCancel cancels the demo and sends the cancellation to both sides.
+func BookingCancel(c *gin.Context) {
+	b, ok := bookingFromToken(c, purposeBookingCancel, c.PostForm("t"))
+	if !ok {
+		return
+	}
+	b, err := demoScheduler.Cancel(b.ID)
+	if err != nil && !errors.Is(err, booking.ErrCanceled) {
+		log.Printf("Error canceling booking: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if err == nil {
+		if err := sendBookingInvites(b, "Your RobusTest demo is canceled", ""); err != nil {
+			log.Printf("Failed to send cancellation for booking %s: %v", b.ID, err)
+		}
+	}
+	renderBookingPage(c, http.StatusOK, bookingView(b, "canceled", ""))
+}
+
+// BookingReschedulePage shows the slot picker for moving a demo.
+func BookingReschedulePage(c *gin.Context) {
+	token := c.Query("t")
+	if b, ok := bookingFromToken(c, purposeBookingReschedule, token); ok {
+		renderBookingPage(c, http.StatusOK, bookingView(b, "reschedule", token))
+	}
+}
+
+// BookingReschedule moves the demo to the chosen slot and sends the
+// updated invite.
+func BookingReschedule(c *gin.Context) {
+	token := c.PostForm("t")
+	b, ok := bookingFromToken(c, purposeBookingReschedule, token)
+	if !ok {
+		return
+	}
+	start, err := time.Parse(time.RFC3339, c.PostForm("slot"))
+	if err != nil {
+		v := bookingView(b, "reschedule", token)
+		v.Message = "Pick one of the listed times."
+		renderBookingPage(c, http.StatusBadRequest, v)
+		return
+	}
+	moved, err := demoScheduler.Reschedule(b.ID, start)
+	if errors.Is(err, booking.ErrSlotTaken) {
+		v := bookingView(b, "reschedule", token)
+		v.Message = "Someone just booked that time. Please pick another."
+		renderBookingPage(c, http.StatusConflict, v)
+		return
+	}
+	if err != nil {
+		log.Printf("Error rescheduling booking: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if err := sendBookingInvites(moved, "Your RobusTest demo has moved", ""); err != nil {
+		log.Printf("Failed to send updated invite for booking %s: %v", moved.ID, err)
+	}
+	renderBookingPage(c, http.StatusOK, bookingView(moved, "rescheduled", ""))
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/i18n"
+	"github.com/izinga/robustest-web/internal/app/site"
+)
+
+// htmlCacheControl lets browsers keep pages but revalidate them on every
+// use, and a CDN serve them for five minutes before doing the same.
+const htmlCacheControl = "public, max-age=0, s-maxage=300, must-revalidate"
+
+// ConditionalGET gives responses that are fixed by the build or by the
+// synced docs tree validators (weak ETags for HTML, see weakETag), and
+// answers a matching If-None-Match (or, without one, If-Modified-Since)
+// with 304 before anything is rendered:
+//
+//   - registered pages, in every locale, by version, locale and path;
+//   - docs pages, /docs/index.json and the docs changelog, by version,
+//     docs SHA and path;
+//   - the sitemaps, by version and docs SHA.
+//
+// version identifies the build; a "dev" build is told apart by its start
+// time. llms.txt and the Markdown variants validate themselves (see
+// serveText), and docs assets are served with their file's validators.
+func ConditionalGET(version string) gin.HandlerFunc {
+	started := time.Now().UTC().Truncate(time.Second)
+	if version == "dev" {
+		version += "-" + started.Format(time.RFC3339)
+	}
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		etag, modified, cacheControl, ok := validators(c.Request.URL.Path, version, started)
+		if !ok {
+			c.Next()
+			return
+		}
+		// Cookies set so far (attribution on a campaign landing, a language
+		// choice) are the visitor's own, and a page picked by the rt_lang
+		// cookie is marked private by NegotiateLocale; a shared cache must
+		// not hand either to anyone else.
+		if len(c.Writer.Header().Values("Set-Cookie")) > 0 || strings.HasPrefix(c.Writer.Header().Get("Cache-Control"), "private") {
+			cacheControl = "private, no-cache"
+		}
+		c.Header("ETag", etag)
+		c.Header("Last-Modified", modified.Format(http.TimeFormat))
+		c.Header("Cache-Control", cacheControl)
+		if notModified(c.Request, etag, modified) {
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+		c.Next()
+	}
+}
+
+// validators returns the ETag, Last-Modified and Cache-Control for the
+// response at path, or false if it has none.
+func validators(path, version string, started time.Time) (etag string, modified time.Time, cacheControl string, ok bool) {
+	docsSHA, docsAt := "", time.Time{}
+	if docsStore != nil && docsStore.Ready() {
+		docsSHA, docsAt = docsStore.Published()
+	}
+
+	switch {
+	case path == "/sitemap.xml" || strings.HasPrefix(path, "/sitemaps/"):
+		return strongETag(version, docsSHA, path), latest(started, docsAt), "public, max-age=3600", true
+
+	case path == "/docs" || strings.HasPrefix(path, "/docs/"):
+		rest := strings.TrimPrefix(path, "/docs/")
+		if docsSHA == "" || rest == "refresh" || strings.HasPrefix(rest, "assets/") || strings.HasSuffix(rest, ".md") {
+			return "", time.Time{}, "", false
+		}
+		// The build is part of the tag: a deploy can change the docs
+		// templates without a new docs SHA.
+		modified = latest(started, docsAt)
+		if rest == "index.json" || rest == "changes.atom" || rest == "changes.json" {
+			return strongETag("docs", version, docsSHA, path), modified, "public, max-age=300", true
+		}
+		return weakETag("docs", version, docsSHA, path), modified, htmlCacheControl, true
+	}
+
+	// The locale is part of the tag, so a cache that keys on
+	// Accept-Language never revalidates one language's copy with another's.
+	if p, l, found := registeredPage(path); found && !p.Dynamic {
+		return weakETag(version, l.Code, path), started, htmlCacheControl, true
+	}
+	return "", time.Time{}, "", false
+}
+
+// registeredPage looks up the registered page served at path, with or
+// without a locale prefix, and the locale it is served in.
+func registeredPage(path string) (site.Page, i18n.Locale, bool) {
+	for _, l := range i18n.Locales {
+		if l == i18n.Default {
+			continue
+		}
+		prefix := "/" + l.Code
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		rest := strings.TrimPrefix(path, prefix)
+		if rest == "" {
+			rest = "/"
+		}
+		p, ok := site.Lookup(rest)
+		return p, l, ok && p.Localized
+	}
+	p, ok := site.Lookup(path)
+	return p, i18n.Default, ok
+}
+
+// strongETag hashes parts into a quoted strong entity tag.
+func strongETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// weakETag is strongETag marked weak, for HTML: pages are equivalent for
+// the same parts but not byte for byte, since every response carries its
+// own CSP nonce.
+func weakETag(parts ...string) string {
+	return "W/" + strongETag(parts...)
+}
+
+// notModified reports whether the request's validators match. Per RFC 9110
+// If-Modified-Since is ignored when If-None-Match is present.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.IsZero() && !modified.After(since)
+}
+
+// latest returns the later of two times, truncated to HTTP date precision.
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		a = b
+	}
+	return a.UTC().Truncate(time.Second)
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"log"
@@ -9,30 +11,48 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/izinga/robustest-web/internal/app/views/components"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/izinga/robustest-web/internal/app/booking"
+	"github.com/izinga/robustest-web/internal/app/leads"
+	"github.com/izinga/robustest-web/internal/app/redact"
+	"github.com/izinga/robustest-web/internal/app/tokens"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 )
 
-// contactFormLogger is a dedicated logger for contact form submissions
-var contactFormLogger *log.Logger
+// leadDispatcher delivers accepted leads to the configured CRM sinks.
+var leadDispatcher = leads.NewDispatcher()
 
-func init() {
-	// Open or create the contact form log file
-	logFile, err := os.OpenFile("contact_form.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Printf("Warning: Could not open contact_form.log: %v", err)
-		contactFormLogger = log.New(os.Stdout, "[CONTACT] ", log.LstdFlags)
-	} else {
-		contactFormLogger = log.New(logFile, "", log.LstdFlags)
-	}
+// leadStore keeps every accepted lead with its attribution for reporting.
+var leadStore *leads.Store
+
+// linkSigner signs the links we email to visitors (see package tokens).
+var linkSigner *tokens.Signer
+
+// InitLeads configures lead storage, CRM delivery and link signing from the
+// environment (see leads.StoreFromEnv, leads.DispatcherFromEnv and
+// tokens.SignerFromEnv).
+func InitLeads() {
+	leadStore = leads.StoreFromEnv()
+	leadDispatcher = leads.DispatcherFromEnv()
+	linkSigner = tokens.SignerFromEnv()
 }
 
+// WaitLeads blocks until in-flight CRM deliveries finish or ctx expires.
+func WaitLeads(ctx context.Context) error {
+	return leadDispatcher.Wait(ctx)
+}
+
+// contactFormLogger is a dedicated logger for contact form submissions. It
+// writes to stdout until InitRetention opens the rotating contact log.
+var contactFormLogger = log.New(os.Stdout, "[CONTACT] ", log.LstdFlags)
+
 // logContactForm logs contact form submission to the dedicated log file
 func logContactForm(req ContactFormRequest, status string, emailErr error) {
 	errMsg := ""
@@ -66,7 +86,7 @@ type rateLimiter struct {
 
 var contactRateLimiter = &rateLimiter{
 	requests: make(map[string][]time.Time),
-	limit:    5,              // 5 requests
+	limit:    5,               // 5 requests
 	window:   5 * time.Minute, // per 5 minutes
 }
 
@@ -155,12 +175,33 @@ var phoneRegex = regexp.MustCompile(`^[\d\s\-\+\(\)]{0,20}$`)
 
 // ContactFormRequest represents the contact form submission
 type ContactFormRequest struct {
-	Name     string `form:"name" binding:"required,max=100"`
-	Email    string `form:"email" binding:"required,email,max=254"`
-	Company  string `form:"company" binding:"max=200"`
-	Phone    string `form:"phone" binding:"max=20"`
-	Message  string `form:"message" binding:"max=2000"`
-	LeadType string `form:"lead_type" binding:"max=20"`
+	Name     string `form:"name" json:"name" binding:"required,max=100"`
+	Email    string `form:"email" json:"email" binding:"required,email,max=254"`
+	Company  string `form:"company" json:"company" binding:"max=200"`
+	Phone    string `form:"phone" json:"phone" binding:"max=20"`
+	Message  string `form:"message" json:"message" binding:"max=2000"`
+	LeadType string `form:"lead_type" json:"lead_type" binding:"max=20"`
+
+	// SourcePage is the site page the visitor came from before /contact;
+	// API clients may send their own attribution instead of the cookie.
+	SourcePage  string            `form:"source_page" json:"source_page" binding:"max=200"`
+	Attribution leads.Attribution `form:"-" json:"attribution"`
+
+	// Qualification answers from steps 2 and 3 of the form. The htmx form
+	// requires them; API clients may omit them (see validateQualification).
+	DeviceCount int      `form:"device_count" json:"device_count" binding:"min=0,max=100000"`
+	Platforms   []string `form:"platforms" json:"platforms" binding:"max=16,dive,max=20"`
+	Frameworks  []string `form:"frameworks" json:"frameworks" binding:"max=16,dive,max=20"`
+	Deployment  string   `form:"deployment" json:"deployment" binding:"max=20"`
+	Timeline    string   `form:"timeline" json:"timeline" binding:"max=20"`
+
+	// Slot is an optional demo start time (RFC 3339) from the slot picker;
+	// TimeZone is the visitor's IANA zone, used to word the invite.
+	Slot     string `form:"slot" json:"slot" binding:"max=40"`
+	TimeZone string `form:"tz" json:"time_zone" binding:"max=64"`
+
+	// booked is the demo reserved for this submission, if any.
+	booked *booking.Booking
 }
 
 // sanitize cleans and validates the contact form request
@@ -171,6 +212,10 @@ func (req *ContactFormRequest) sanitize() error {
 	req.Company = strings.TrimSpace(req.Company)
 	req.Phone = strings.TrimSpace(req.Phone)
 	req.Message = strings.TrimSpace(req.Message)
+	req.SourcePage = strings.TrimSpace(req.SourcePage)
+	if !strings.HasPrefix(req.SourcePage, "/") {
+		req.SourcePage = ""
+	}
 
 	// Only a known lead type survives; anything else is treated as a demo lead
 	if req.LeadType != "partner" {
@@ -179,17 +224,30 @@ func (req *ContactFormRequest) sanitize() error {
 
 	// Validate email format with stricter regex
 	if !emailRegex.MatchString(req.Email) {
-		return fmt.Errorf("invalid email format")
+		return &fieldError{Field: "email", Message: "Enter a valid email address."}
 	}
 
 	// Validate phone format if provided
 	if req.Phone != "" && !phoneRegex.MatchString(req.Phone) {
-		return fmt.Errorf("invalid phone number format")
+		return &fieldError{Field: "phone", Message: "Use digits, spaces, and + - ( ) only."}
 	}
 
 	return nil
 }
 
+// lead converts a sanitized request into the record handed to CRM sinks.
+func (req ContactFormRequest) lead() leads.Lead {
+	lead := leads.New(req.LeadType)
+	lead.Name = req.Name
+	lead.Email = req.Email
+	lead.Company = req.Company
+	lead.Phone = req.Phone
+	lead.Message = req.Message
+	lead.Qualification = req.qualification()
+	lead.Attribution = req.Attribution
+	return lead
+}
+
 // turnstileResponse represents the Cloudflare Turnstile siteverify response
 type turnstileResponse struct {
 	Success    bool     `json:"success"`
@@ -227,16 +285,67 @@ func verifyTurnstile(token, remoteIP string) (bool, error) {
 	return result.Success, nil
 }
 
-// SubmitContactForm handles the contact form submission
+// contactGuardFields are the anti-abuse fields checked before the
+// submission itself is validated: the honeypot and the Turnstile token.
+type contactGuardFields struct {
+	Website        string `json:"website"`
+	TurnstileToken string `json:"turnstile_token"`
+}
+
+// maxContactBodyBytes caps submissions; the form fields' own limits add up
+// to well under this.
+const maxContactBodyBytes = 64 << 10
+
+// readContactGuards extracts the honeypot and Turnstile token from either a
+// form post or a JSON body. JSON bodies are cached by gin so the full
+// request can be bound afterwards. It fails only with an
+// *http.MaxBytesError, for a body over maxContactBodyBytes; other malformed
+// bodies fail again at bindContact.
+func readContactGuards(c *gin.Context) (contactGuardFields, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxContactBodyBytes)
+	var g contactGuardFields
+	var err error
+	if isJSONBody(c) {
+		err = c.ShouldBindBodyWith(&g, binding.JSON)
+	} else {
+		err = c.Request.ParseForm()
+		g = contactGuardFields{
+			Website:        c.PostForm("website"),
+			TurnstileToken: c.PostForm("cf-turnstile-response"),
+		}
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return g, err
+	}
+	return g, nil
+}
+
+// bindContact binds and validates the submission from its form or JSON body.
+func bindContact(c *gin.Context, req *ContactFormRequest) error {
+	if isJSONBody(c) {
+		return c.ShouldBindBodyWith(req, binding.JSON)
+	}
+	return c.ShouldBind(req)
+}
+
+// SubmitContactForm handles the contact form submission. The htmx form gets
+// HTML fragments; API clients posting JSON (or asking for it via Accept)
+// get JSON with machine-readable error codes — see openapi.yaml.
 func SubmitContactForm(c *gin.Context) {
+	guards, err := readContactGuards(c)
+	if err != nil {
+		log.Printf("Contact form body too large from IP: %s", c.ClientIP())
+		contactFail(c, http.StatusRequestEntityTooLarge, codeBodyTooLarge,
+			fmt.Sprintf("The submission is too large (the limit is %d KB).", maxContactBodyBytes>>10), nil)
+		return
+	}
+
 	// Honeypot check — bots fill this hidden field, humans don't
-	if c.PostForm("website") != "" {
+	if guards.Website != "" {
 		log.Printf("Honeypot triggered from IP: %s", c.ClientIP())
 		// Return fake success to avoid revealing detection
-		c.Status(http.StatusOK)
-		if err := components.ContactFormSuccess().Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering honeypot response: %v", err)
-		}
+		contactSucceed(c)
 		return
 	}
 
@@ -244,120 +353,162 @@ func SubmitContactForm(c *gin.Context) {
 	clientIP := c.ClientIP()
 	if !contactRateLimiter.isAllowed(clientIP) {
 		log.Printf("Rate limit exceeded for IP: %s", clientIP)
-		c.Status(http.StatusTooManyRequests)
-		if err := components.ContactFormError("Too many requests. Please wait a few minutes before trying again.").Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering rate limit response: %v", err)
-		}
+		c.Header("Retry-After", strconv.Itoa(int(contactRateLimiter.window.Seconds())))
+		contactFail(c, http.StatusTooManyRequests, codeRateLimited,
+			"Too many requests. Please wait a few minutes before trying again.", nil)
 		return
 	}
 
-	// Cloudflare Turnstile verification
-	turnstileToken := c.PostForm("cf-turnstile-response")
-	if turnstileToken == "" {
-		log.Printf("Missing Turnstile token from IP: %s", clientIP)
-		c.Status(http.StatusBadRequest)
-		if err := components.ContactFormError("Verification failed. Please refresh the page and try again.").Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering turnstile error response: %v", err)
+	// Cloudflare Turnstile verification (partner API keys are exempt)
+	if !contactAPIKeyValid(c) {
+		if guards.TurnstileToken == "" {
+			log.Printf("Missing Turnstile token from IP: %s", clientIP)
+			contactFail(c, http.StatusBadRequest, codeVerificationFailed,
+				"Verification failed. Please refresh the page and try again.", nil)
+			return
 		}
-		return
-	}
 
-	turnstileOK, err := verifyTurnstile(turnstileToken, clientIP)
-	if err != nil {
-		log.Printf("Turnstile verification error: %v", err)
-		c.Status(http.StatusServiceUnavailable)
-		if err := components.ContactFormError("Verification service is temporarily unavailable. Please try again in a moment.").Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering turnstile unavailable response: %v", err)
+		turnstileOK, err := verifyTurnstile(guards.TurnstileToken, clientIP)
+		if err != nil {
+			log.Printf("Turnstile verification error: %v", err)
+			contactFail(c, http.StatusServiceUnavailable, codeVerificationUnavailable,
+				"Verification service is temporarily unavailable. Please try again in a moment.", nil)
+			return
 		}
-		return
-	}
-	if !turnstileOK {
-		log.Printf("Turnstile verification failed for IP: %s", clientIP)
-		c.Status(http.StatusForbidden)
-		if err := components.ContactFormError("Verification failed. Please refresh the page and try again.").Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering turnstile failed response: %v", err)
+		if !turnstileOK {
+			log.Printf("Turnstile verification failed for IP: %s", clientIP)
+			contactFail(c, http.StatusForbidden, codeVerificationFailed,
+				"Verification failed. Please refresh the page and try again.", nil)
+			return
 		}
-		return
 	}
 
 	var req ContactFormRequest
 
-	if err := c.ShouldBind(&req); err != nil {
+	if err := bindContact(c, &req); err != nil {
 		log.Printf("Contact form validation error: %v", err)
-		c.Status(http.StatusBadRequest)
-		if err := components.ContactFormError("Please fill in all required fields correctly.").Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering validation error response: %v", err)
-		}
+		contactInvalid(c, codeValidationFailed,
+			"Please fill in all required fields correctly.", req, validationMessages(err, isJSONBody(c)))
 		return
 	}
 
 	// Sanitize and validate input
 	if err := req.sanitize(); err != nil {
 		log.Printf("Contact form sanitization error: %v", err)
-		c.Status(http.StatusBadRequest)
-		if err := components.ContactFormError("Please check your input and try again.").Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering sanitization error response: %v", err)
-		}
+		contactInvalid(c, codeValidationFailed,
+			"Please check your input and try again.", req, validationMessages(err, isJSONBody(c)))
 		return
 	}
 
 	// Reject disposable email domains
 	if isDisposableEmail(req.Email) {
-		log.Printf("Disposable email rejected: %s from IP: %s", req.Email, clientIP)
-		c.Status(http.StatusBadRequest)
-		if err := components.ContactFormError("Please use a work email address. Temporary or disposable emails are not accepted.").Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering disposable email response: %v", err)
-		}
+		log.Printf("Disposable email rejected: %s from IP: %s", redact.Email(req.Email), clientIP)
+		contactInvalid(c, codeDisposableEmail,
+			"Please use a work email address. Temporary or disposable emails are not accepted.",
+			req, map[string]string{"email": "Use a work email address. Temporary or disposable emails are not accepted."})
+		return
+	}
+
+	// Qualification steps: required from the form, optional for the API
+	fields := req.validateQualification(!wantsJSON(c))
+	for k, v := range req.validateSlot() {
+		fields[k] = v
+	}
+	if len(fields) > 0 {
+		log.Printf("Contact form qualification error: %v", fields)
+		contactInvalid(c, codeValidationFailed,
+			"Please check your input and try again.", req, fields)
 		return
 	}
 
+	// Attribution: explicit values from the request, then the visitor's
+	// first-party cookie
+	if req.Attribution.SourcePage == "" {
+		req.Attribution.SourcePage = req.SourcePage
+	}
+	req.Attribution = mergeAttribution(req.Attribution, readAttribution(c))
+
 	// Check for spam content in name and message
 	if containsSpamContent(req.Name, req.Message) {
-		log.Printf("Spam content detected from IP: %s, email: %s", clientIP, req.Email)
+		log.Printf("Spam content detected from IP: %s, email: %s", clientIP, redact.Email(req.Email))
 		// Return fake success to avoid revealing detection
-		c.Status(http.StatusOK)
-		if err := components.ContactFormSuccess().Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering spam detection response: %v", err)
-		}
+		contactSucceed(c)
 		return
 	}
 
+	lead := req.lead()
+	segment := lead.Segment()
+
+	// Reserve the demo before anything is sent, so losing a race for the
+	// slot is reported to the visitor rather than double-booking anyone
+	if req.Slot != "" {
+		b, err := bookDemo(req, lead.ID)
+		if errors.Is(err, booking.ErrSlotTaken) {
+			contactInvalid(c, codeSlotUnavailable, "That demo time is no longer available.",
+				req, map[string]string{"slot": "Someone just booked that time. Please pick another."})
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to book demo for %s: %v", redact.Email(req.Email), err)
+		} else {
+			req.booked = &b
+		}
+	}
+
 	// Build email content with HTML-escaped values
 	subject := fmt.Sprintf("New Contact Form Submission from %s",
 		html.EscapeString(req.Name))
-	if req.LeadType == "partner" {
+	switch segment {
+	case leads.SegmentPartner:
 		subject = fmt.Sprintf("[PARTNER] New Partner Inquiry from %s",
 			html.EscapeString(req.Name))
+	case leads.SegmentEnterprise:
+		subject = fmt.Sprintf("[ENTERPRISE] New Contact Form Submission from %s",
+			html.EscapeString(req.Name))
 	}
 
 	htmlContent := buildEmailHTML(req)
 	textContent := buildEmailText(req)
 
-	// Send email via SendGrid
-	if err := sendEmail(subject, htmlContent, textContent); err != nil {
+	// Send email via SendGrid to the segment's inbox
+	if err := sendEmail(inboxFor(segment), subject, htmlContent, textContent); err != nil {
 		log.Printf("Failed to send contact email: %v", err)
 		logContactForm(req, "FAILED", err)
-		c.Status(http.StatusInternalServerError)
-		if err := components.ContactFormError("Failed to send your request. Please try again or email us directly at hello@robustest.com").Render(c.Request.Context(), c.Writer); err != nil {
-			log.Printf("Error rendering email error response: %v", err)
+		if req.booked != nil {
+			if _, err := demoScheduler.Cancel(req.booked.ID); err != nil {
+				log.Printf("Failed to release booking %s: %v", req.booked.ID, err)
+			}
 		}
+		contactFail(c, http.StatusInternalServerError, codeSendFailed,
+			"Failed to send your request. Please try again or email us directly at hello@robustest.com", nil)
 		return
 	}
 
 	log.Printf("Contact form submitted successfully: %s <%s>",
-		req.Name, req.Email)
+		redact.Name(req.Name), redact.Email(req.Email))
 	logContactForm(req, "SUCCESS", nil)
 
-	// Send confirmation email to the sender
-	if err := sendConfirmationEmail(req); err != nil {
-		log.Printf("Failed to send confirmation email to %s: %v", req.Email, err)
+	if err := leadStore.Append(lead); err != nil {
+		log.Printf("Failed to store lead %s: %v", lead.ID, err)
+	}
+	leadDispatcher.Dispatch(lead)
+
+	// A booked demo gets its calendar invite, carrying the confirmation
+	// link, instead of the plain confirmation
+	if req.booked != nil {
+		if err := sendBookingInvites(*req.booked, "Your RobusTest demo", leadVerifyURL(lead)); err != nil {
+			log.Printf("Failed to send demo invite for booking %s: %v", req.booked.ID, err)
+		}
+		contactBooked(c, *req.booked)
+		return
 	}
 
-	// Return success response
-	c.Status(http.StatusOK)
-	if err := components.ContactFormSuccess().Render(c.Request.Context(), c.Writer); err != nil {
-		log.Printf("Error rendering success response: %v", err)
+	// Send confirmation email to the sender
+	if err := sendConfirmationEmail(req, lead); err != nil {
+		log.Printf("Failed to send confirmation email to %s: %v", redact.Email(req.Email), err)
 	}
+
+	contactSucceed(c)
 }
 
 func buildEmailHTML(req ContactFormRequest) string {
@@ -425,6 +576,26 @@ func buildEmailHTML(req ContactFormRequest) string {
             </div>`)
 	}
 
+	if demo := req.demoSummary(); demo != "" {
+		sb.WriteString(`
+            <div class="field">
+                <div class="label">Demo booked</div>
+                <div class="value">` + html.EscapeString(demo) + `</div>
+            </div>`)
+	}
+
+	if qual := qualificationRows(req.qualification()); len(qual) > 0 {
+		sb.WriteString(`
+            <div class="field">
+                <div class="label">Lab</div>
+                <div class="value">`)
+		for _, row := range qual {
+			sb.WriteString(html.EscapeString(row[0]) + `: ` + html.EscapeString(row[1]) + `<br>`)
+		}
+		sb.WriteString(`</div>
+            </div>`)
+	}
+
 	if req.Message != "" {
 		sb.WriteString(`
             <div class="field">
@@ -433,6 +604,18 @@ func buildEmailHTML(req ContactFormRequest) string {
             </div>`)
 	}
 
+	if attr := attributionRows(req.Attribution); len(attr) > 0 {
+		sb.WriteString(`
+            <div class="field">
+                <div class="label">Attribution</div>
+                <div class="value">`)
+		for _, row := range attr {
+			sb.WriteString(html.EscapeString(row[0]) + `: ` + html.EscapeString(row[1]) + `<br>`)
+		}
+		sb.WriteString(`</div>
+            </div>`)
+	}
+
 	sb.WriteString(`
         </div>
         <div class="footer">
@@ -445,6 +628,28 @@ func buildEmailHTML(req ContactFormRequest) string {
 	return sb.String()
 }
 
+// attributionRows lists the non-empty attribution values in reading order
+// as label/value pairs for the internal notification email.
+func attributionRows(a leads.Attribution) [][2]string {
+	all := [][2]string{
+		{"Source page", a.SourcePage},
+		{"Landing page", a.LandingPage},
+		{"Referrer", a.Referrer},
+		{"UTM source", a.UTMSource},
+		{"UTM medium", a.UTMMedium},
+		{"UTM campaign", a.UTMCampaign},
+		{"UTM term", a.UTMTerm},
+		{"UTM content", a.UTMContent},
+	}
+	var rows [][2]string
+	for _, row := range all {
+		if row[1] != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
 func buildEmailText(req ContactFormRequest) string {
 	var sb strings.Builder
 
@@ -464,166 +669,188 @@ func buildEmailText(req ContactFormRequest) string {
 		sb.WriteString(fmt.Sprintf("Phone: %s\n", req.Phone))
 	}
 
+	if demo := req.demoSummary(); demo != "" {
+		sb.WriteString(fmt.Sprintf("Demo booked: %s\n", demo))
+	}
+
+	if qual := qualificationRows(req.qualification()); len(qual) > 0 {
+		sb.WriteString("\nLab:\n")
+		for _, row := range qual {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", row[0], row[1]))
+		}
+	}
+
 	if req.Message != "" {
 		sb.WriteString(fmt.Sprintf("\nMessage:\n%s\n", req.Message))
 	}
 
+	if attr := attributionRows(req.Attribution); len(attr) > 0 {
+		sb.WriteString("\nAttribution:\n")
+		for _, row := range attr {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", row[0], row[1]))
+		}
+	}
+
 	sb.WriteString("\n---\nSubmitted via RobusTest website contact form")
 
 	return sb.String()
 }
 
-func sendEmail(subject, htmlContent, textContent string) error {
-	apiKey := os.Getenv("SENDGRID_API_KEY")
-	if apiKey == "" {
-		return fmt.Errorf("SENDGRID_API_KEY environment variable not set")
+// inboxFor returns the address a lead of the given segment is sent to:
+// CONTACT_TO_EMAIL_<SEGMENT> (e.g. CONTACT_TO_EMAIL_ENTERPRISE) when set,
+// otherwise CONTACT_TO_EMAIL, otherwise hello@robustest.com.
+func inboxFor(segment string) string {
+	if to := os.Getenv("CONTACT_TO_EMAIL_" + strings.ToUpper(segment)); to != "" {
+		return to
 	}
-
-	fromEmail := os.Getenv("CONTACT_FROM_EMAIL")
-	if fromEmail == "" {
-		fromEmail = "noreply@robustest.com"
+	if to := os.Getenv("CONTACT_TO_EMAIL"); to != "" {
+		return to
 	}
+	return "hello@robustest.com"
+}
 
-	toEmail := os.Getenv("CONTACT_TO_EMAIL")
-	if toEmail == "" {
-		toEmail = "hello@robustest.com"
+// siteURL returns the absolute URL of path on the public site (SITE_URL,
+// default https://robustest.com), for links in emails.
+func siteURL(path string) string {
+	base := os.Getenv("SITE_URL")
+	if base == "" {
+		base = "https://robustest.com"
 	}
+	return strings.TrimRight(base, "/") + path
+}
 
-	from := mail.NewEmail("RobusTest Website", fromEmail)
-	to := mail.NewEmail("RobusTest Team", toEmail)
+// contactFromEmail is the verified SendGrid sender for all site email.
+func contactFromEmail() string {
+	if from := os.Getenv("CONTACT_FROM_EMAIL"); from != "" {
+		return from
+	}
+	return "noreply@robustest.com"
+}
 
-	message := mail.NewSingleEmail(from, subject, to, textContent, htmlContent)
+// sendMail delivers a prepared message through SendGrid and returns the
+// response status.
+func sendMail(message *mail.SGMailV3) (int, error) {
+	apiKey := os.Getenv("SENDGRID_API_KEY")
+	if apiKey == "" {
+		return 0, fmt.Errorf("SENDGRID_API_KEY environment variable not set")
+	}
 
 	client := sendgrid.NewSendClient(apiKey)
 	response, err := client.Send(message)
 
 	if err != nil {
-		return fmt.Errorf("sendgrid error: %w", err)
+		return 0, fmt.Errorf("sendgrid error: %w", err)
 	}
 
 	if response.StatusCode >= 400 {
-		return fmt.Errorf("sendgrid returned status %d: %s", response.StatusCode, response.Body)
+		return response.StatusCode, fmt.Errorf("sendgrid returned status %d: %s", response.StatusCode, response.Body)
+	}
+	return response.StatusCode, nil
+}
+
+func sendEmail(toEmail, subject, htmlContent, textContent string) error {
+	from := mail.NewEmail("RobusTest Website", contactFromEmail())
+	to := mail.NewEmail("RobusTest Team", toEmail)
+
+	message := mail.NewSingleEmail(from, subject, to, textContent, htmlContent)
+
+	status, err := sendMail(message)
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Email sent successfully, status: %d", response.StatusCode)
+	log.Printf("Email sent successfully, status: %d", status)
 	return nil
 }
 
-// sendConfirmationEmail sends a thank you email to the sender
-func sendConfirmationEmail(req ContactFormRequest) error {
-	apiKey := os.Getenv("SENDGRID_API_KEY")
-	if apiKey == "" {
-		return fmt.Errorf("SENDGRID_API_KEY environment variable not set")
-	}
+// verifyTTL is how long the link in the confirmation email works.
+const verifyTTL = 7 * 24 * time.Hour
 
-	fromEmail := os.Getenv("CONTACT_FROM_EMAIL")
-	if fromEmail == "" {
-		fromEmail = "noreply@robustest.com"
-	}
+// purposeLeadVerify is the token purpose of confirmation links.
+const purposeLeadVerify = "lead-verify"
 
-	from := mail.NewEmail("RobusTest", fromEmail)
-	to := mail.NewEmail(req.Name, req.Email)
-	subject := "Thank you for contacting RobusTest"
+// leadVerifyURL returns the signed link that confirms lead's email address.
+func leadVerifyURL(lead leads.Lead) string {
+	token := linkSigner.Sign(purposeLeadVerify, lead.ID, time.Now().Add(verifyTTL))
+	return siteURL("/contact/verify?t=" + url.QueryEscape(token))
+}
 
-	htmlContent := buildConfirmationHTML(req)
-	textContent := buildConfirmationText(req)
+// sendConfirmationEmail asks the sender to confirm their address. Anyone
+// can type anyone's address into the form, so the email repeats nothing
+// that was submitted, not even the name; it only carries the link that
+// marks the lead verified.
+func sendConfirmationEmail(req ContactFormRequest, lead leads.Lead) error {
+	from := mail.NewEmail("RobusTest", contactFromEmail())
+	to := mail.NewEmail("", req.Email)
+	subject := "Confirm your email address for RobusTest"
 
-	message := mail.NewSingleEmail(from, subject, to, textContent, htmlContent)
+	htmlContent, textContent := buildNoticeEmail("", []string{
+		"Thanks for getting in touch with RobusTest. We received a request from this address on robustest.com and will reply soon.",
+		"Please confirm the address is yours. If you didn't contact us, ignore this email and you won't hear from us again.",
+	}, [][2]string{{"Confirm your email address", leadVerifyURL(lead)}})
 
-	client := sendgrid.NewSendClient(apiKey)
-	response, err := client.Send(message)
+	message := mail.NewSingleEmail(from, subject, to, textContent, htmlContent)
 
+	status, err := sendMail(message)
 	if err != nil {
-		return fmt.Errorf("sendgrid error: %w", err)
+		return err
 	}
 
-	if response.StatusCode >= 400 {
-		return fmt.Errorf("sendgrid returned status %d: %s", response.StatusCode, response.Body)
-	}
-
-	log.Printf("Confirmation email sent to %s, status: %d", req.Email, response.StatusCode)
+	log.Printf("Confirmation email sent to %s, status: %d", redact.Email(req.Email), status)
 	return nil
 }
 
-func buildConfirmationHTML(req ContactFormRequest) string {
-	name := html.EscapeString(req.Name)
-	company := html.EscapeString(req.Company)
-	message := html.EscapeString(req.Message)
-
-	var sb strings.Builder
-
-	sb.WriteString(`<!DOCTYPE html>
-<html>
-<head>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background: #2563eb; color: white; padding: 20px; border-radius: 8px 8px 0 0; }
-        .content { background: #f9fafb; padding: 20px; border: 1px solid #e5e7eb; }
-        .message-box { background: white; padding: 15px; border-radius: 4px; border: 1px solid #e5e7eb; margin-top: 15px; }
-        .field { margin-bottom: 10px; }
-        .label { font-weight: bold; color: #6b7280; font-size: 12px; text-transform: uppercase; }
-        .value { margin-top: 4px; }
-        .footer { padding: 15px; font-size: 12px; color: #6b7280; text-align: center; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h2 style="margin: 0;">Thank You for Contacting Us</h2>
-        </div>
-        <div class="content">
-            <p>Hi ` + name + `,</p>
-            <p>Thank you for reaching out to RobusTest. We have received your message and will get back to you soon.</p>
-            <div class="message-box">
-                <p style="margin-top: 0; font-weight: bold; color: #374151;">Your message:</p>`)
-
-	if req.Company != "" {
-		sb.WriteString(`
-                <div class="field">
-                    <div class="label">Company</div>
-                    <div class="value">` + company + `</div>
-                </div>`)
+// sendNotice sends a plain transactional email through sendMail.
+func sendNotice(to *mail.Email, subject, htmlContent, textContent string) error {
+	from := mail.NewEmail("RobusTest", contactFromEmail())
+	message := mail.NewSingleEmail(from, subject, to, textContent, htmlContent)
+	status, err := sendMail(message)
+	if err != nil {
+		return err
 	}
+	log.Printf("Email sent to %s, status: %d", redact.Email(to.Address), status)
+	return nil
+}
 
-	if req.Message != "" {
-		sb.WriteString(`
-                <div class="field">
-                    <div class="label">Message</div>
-                    <div class="value">` + strings.ReplaceAll(message, "\n", "<br>") + `</div>
-                </div>`)
+// buildNoticeEmail renders a short transactional email as HTML and text:
+// a greeting (generic when name is empty), one paragraph per line, and a
+// row of links.
+func buildNoticeEmail(name string, lines []string, links [][2]string) (string, string) {
+	var h, t strings.Builder
+	h.WriteString(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <p>` + html.EscapeString(greeting(name)) + `</p>`)
+	t.WriteString(greeting(name) + "\n\n")
+	for _, line := range lines {
+		h.WriteString("\n        <p>" + html.EscapeString(line) + "</p>")
+		t.WriteString(line + "\n\n")
+	}
+	if len(links) > 0 {
+		h.WriteString("\n        <p>")
+		for i, l := range links {
+			if i > 0 {
+				h.WriteString(" · ")
+			}
+			h.WriteString(`<a href="` + html.EscapeString(l[1]) + `">` + html.EscapeString(l[0]) + `</a>`)
+			t.WriteString(l[0] + ": " + l[1] + "\n")
+		}
+		h.WriteString("</p>")
+		t.WriteString("\n")
 	}
-
-	sb.WriteString(`
-            </div>
-        </div>
-        <div class="footer">
-            RobusTest<br>
-            <a href="https://robustest.com">robustest.com</a>
-        </div>
+	h.WriteString(`
+        <p style="font-size: 12px; color: #6b7280;">RobusTest · <a href="https://robustest.com">robustest.com</a></p>
     </div>
 </body>
 </html>`)
-
-	return sb.String()
+	t.WriteString("---\nRobusTest\nhttps://robustest.com")
+	return h.String(), t.String()
 }
 
-func buildConfirmationText(req ContactFormRequest) string {
-	var sb strings.Builder
-
-	sb.WriteString(fmt.Sprintf("Hi %s,\n\n", req.Name))
-	sb.WriteString("Thank you for reaching out to RobusTest. We have received your message and will get back to you soon.\n\n")
-	sb.WriteString("--- Your message ---\n\n")
-
-	if req.Company != "" {
-		sb.WriteString(fmt.Sprintf("Company: %s\n", req.Company))
-	}
-
-	if req.Message != "" {
-		sb.WriteString(fmt.Sprintf("Message: %s\n", req.Message))
+func greeting(name string) string {
+	if name == "" {
+		return "Hello,"
 	}
-
-	sb.WriteString("\n---\nRobusTest\nhttps://robustest.com")
-
-	return sb.String()
+	return "Hi " + name + ","
 }
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/izinga/robustest-web/internal/app/leads"
+	"github.com/izinga/robustest-web/internal/app/views/components"
+)
+
+// Machine-readable error codes returned by /api/contact in JSON mode. They
+// are part of the public contract documented in openapi.yaml.
+const (
+	codeRateLimited             = "rate_limited"
+	codeVerificationFailed      = "verification_failed"
+	codeVerificationUnavailable = "verification_unavailable"
+	codeValidationFailed        = "validation_failed"
+	codeDisposableEmail         = "disposable_email"
+	codeSendFailed              = "send_failed"
+	codeSlotUnavailable         = "slot_unavailable"
+	codeBodyTooLarge            = "body_too_large"
+)
+
+// contactAPIError is the JSON error body for /api/contact.
+type contactAPIError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// fieldError is a validation failure attributable to one form field.
+type fieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *fieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// isJSONBody reports whether the request body is JSON rather than a form.
+func isJSONBody(c *gin.Context) bool {
+	return strings.HasPrefix(c.ContentType(), "application/json")
+}
+
+// wantsJSON reports whether the caller is an API client rather than the
+// htmx form: a JSON body or an Accept header preferring JSON selects the
+// JSON mode. htmx requests always get HTML fragments.
+func wantsJSON(c *gin.Context) bool {
+	if c.GetHeader("HX-Request") != "" {
+		return false
+	}
+	if isJSONBody(c) {
+		return true
+	}
+	return c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON
+}
+
+// contactFail answers a rejected submission in the caller's format: a JSON
+// error with a code (and per-field messages where known) for API clients,
+// or the ContactFormError fragment for the htmx form.
+func contactFail(c *gin.Context, status int, code, message string, fields map[string]string) {
+	if wantsJSON(c) {
+		c.JSON(status, contactAPIError{Code: code, Message: message, Fields: fields})
+		return
+	}
+	c.Status(status)
+	if err := components.ContactFormError(message).Render(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("Error rendering %s response: %v", code, err)
+	}
+}
+
+// contactInvalid answers a submission that failed validation. API clients
+// get the JSON error; the htmx form is re-rendered with the submitted values
+// preserved and each message next to its input.
+func contactInvalid(c *gin.Context, code, message string, req ContactFormRequest, fields map[string]string) {
+	if wantsJSON(c) || len(fields) == 0 {
+		contactFail(c, http.StatusBadRequest, code, message, fields)
+		return
+	}
+	c.Status(http.StatusBadRequest)
+	if err := components.ContactForm(req.formValues(fields)).Render(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("Error rendering %s form: %v", code, err)
+	}
+}
+
+// formValues converts the request back into the form's view model.
+func (req ContactFormRequest) formValues(errs map[string]string) components.ContactFormValues {
+	v := components.ContactFormValues{
+		Name:       req.Name,
+		Email:      req.Email,
+		Company:    req.Company,
+		Phone:      req.Phone,
+		Message:    req.Message,
+		SourcePage: req.SourcePage,
+		Platforms:  req.Platforms,
+		Frameworks: req.Frameworks,
+		Deployment: req.Deployment,
+		Timeline:   req.Timeline,
+		Slot:       req.Slot,
+		TimeZone:   req.TimeZone,
+		Step:       firstErrorStep(errs),
+		Errors:     errs,
+	}
+	if req.DeviceCount > 0 {
+		v.DeviceCount = strconv.Itoa(req.DeviceCount)
+		v.Tier = leads.TierFor(req.DeviceCount).String()
+	}
+	if req.LeadType == "partner" {
+		v.LeadType = "partner"
+	}
+	return v
+}
+
+// contactSucceed answers an accepted (or silently discarded) submission.
+func contactSucceed(c *gin.Context) {
+	if wantsJSON(c) {
+		c.JSON(http.StatusOK, gin.H{"status": "received"})
+		return
+	}
+	c.Status(http.StatusOK)
+	if err := components.ContactFormSuccess().Render(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("Error rendering success response: %v", err)
+	}
+}
+
+// contactFieldName returns the name clients submit a ContactFormRequest
+// field under: its json tag in JSON bodies, its form tag otherwise (the
+// two differ, e.g. time_zone and tz).
+func contactFieldName(field string, jsonBody bool) string {
+	field, _, _ = strings.Cut(field, "[") // Platforms[2] is reported as platforms
+	tag := "form"
+	if jsonBody {
+		tag = "json"
+	}
+	if f, ok := reflect.TypeOf(ContactFormRequest{}).FieldByName(field); ok {
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(field)
+}
+
+// validationMessages translates binding and sanitize errors into
+// human-readable messages keyed by submitted field name, as named in a JSON
+// body when jsonBody is set. It returns nil when the error is not
+// attributable to individual fields (e.g. malformed JSON).
+func validationMessages(err error, jsonBody bool) map[string]string {
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		return map[string]string{fe.Field: fe.Message}
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+	fields := make(map[string]string, len(verrs))
+	for _, v := range verrs {
+		name := contactFieldName(v.Field(), jsonBody)
+		switch v.Tag() {
+		case "required":
+			fields[name] = "This field is required."
+		case "email":
+			fields[name] = "Enter a valid email address."
+		case "max":
+			fields[name] = fmt.Sprintf("Must be %s characters or fewer.", v.Param())
+			if v.Kind() == reflect.Int || v.Kind() == reflect.Slice {
+				fields[name] = fmt.Sprintf("Must be at most %s.", v.Param())
+			}
+		default:
+			fields[name] = "This value is not valid."
+		}
+	}
+	return fields
+}
+
+// contactAPIKeyValid reports whether the request carries one of the partner
+// API keys in CONTACT_API_KEYS (comma-separated). Server-to-server callers
+// authenticated this way cannot solve a Turnstile challenge, so they skip it.
+func contactAPIKeyValid(c *gin.Context) bool {
+	given := c.GetHeader("X-API-Key")
+	if given == "" {
+		return false
+	}
+	for _, key := range strings.Split(os.Getenv("CONTACT_API_KEYS"), ",") {
+		key = strings.TrimSpace(key)
+		if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(given)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// ContactCORS allows browser submissions to /api/contact from the partner
+// origins listed in CONTACT_CORS_ORIGINS (comma-separated, exact match such
+// as "https://partner.example"). Other origins get no CORS headers, so
+// browsers block them; same-origin form posts are unaffected.
+func ContactCORS() gin.HandlerFunc {
+	allowed := make(map[string]bool)
+	for _, o := range strings.Split(os.Getenv("CONTACT_CORS_ORIGINS"), ",") {
+		if o = strings.TrimRight(strings.TrimSpace(o), "/"); o != "" {
+			allowed[o] = true
+		}
+	}
+	return func(c *gin.Context) {
+		// Every answer depends on Origin, including those without CORS
+		// headers, so a shared cache must not hand one origin's to another.
+		c.Writer.Header().Add("Vary", "Origin")
+		origin := c.GetHeader("Origin")
+		if origin != "" && allowed[origin] {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Methods", "POST, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Content-Type, Accept, X-API-Key")
+			c.Header("Access-Control-Max-Age", "600")
+		}
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+//go:embed openapi.yaml
+var contactOpenAPISpec []byte
+
+// ContactOpenAPI serves the OpenAPI description of /api/contact.
+func ContactOpenAPI(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", contactOpenAPISpec)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestValidationMessagesUseSubmittedNames(t *testing.T) {
+	req := ContactFormRequest{
+		Name:      "Ada",
+		Email:     "ada@example.com",
+		TimeZone:  strings.Repeat("x", 65),
+		Platforms: []string{strings.Repeat("y", 21)},
+	}
+	err := binding.Validator.ValidateStruct(&req)
+	if err == nil {
+		t.Fatal("over-long time zone and platform passed validation")
+	}
+	for _, tc := range []struct {
+		jsonBody bool
+		want     []string
+		notWant  string
+	}{
+		{true, []string{"time_zone", "platforms"}, "tz"},
+		{false, []string{"tz", "platforms"}, "time_zone"},
+	} {
+		msgs := validationMessages(err, tc.jsonBody)
+		for _, name := range tc.want {
+			if _, ok := msgs[name]; !ok {
+				t.Errorf("jsonBody=%v: no message for %q in %v", tc.jsonBody, name, msgs)
+			}
+		}
+		if _, ok := msgs[tc.notWant]; ok {
+			t.Errorf("jsonBody=%v: message keyed %q, which the client did not send", tc.jsonBody, tc.notWant)
+		}
+	}
+}
+
+func TestOpenAPIDocumentsContactRequest(t *testing.T) {
+	spec := string(contactOpenAPISpec)
+	typ := reflect.TypeOf(ContactFormRequest{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if !strings.Contains(spec, "\n        "+name+":") {
+			t.Errorf("openapi.yaml does not document the request field %q", name)
+		}
+	}
+	for _, code := range []string{
+		codeRateLimited, codeVerificationFailed, codeVerificationUnavailable, codeValidationFailed,
+		codeDisposableEmail, codeSendFailed, codeSlotUnavailable, codeBodyTooLarge,
+	} {
+		if !strings.Contains(spec, "\n            - "+code+"\n") {
+			t.Errorf("openapi.yaml does not list the error code %q", code)
+		}
+	}
+}
+
+func TestOversizedContactBody(t *testing.T) {
+	big := strings.Repeat("x", maxContactBodyBytes)
+	jsonBody, _ := json.Marshal(map[string]string{"name": "Ada", "email": "ada@example.com", "message": big})
+	for _, tc := range []struct {
+		contentType string
+		body        string
+	}{
+		{"application/json", string(jsonBody)},
+		{"application/x-www-form-urlencoded", url.Values{"name": {"Ada"}, "message": {big}}.Encode()},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/contact", strings.NewReader(tc.body))
+		c.Request.Header.Set("Content-Type", tc.contentType)
+		c.Request.Header.Set("Accept", "application/json")
+		SubmitContactForm(c)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: status %d, want 413", tc.contentType, w.Code)
+			continue
+		}
+		var got contactAPIError
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Code != codeBodyTooLarge {
+			t.Errorf("%s: body %s, want code %s", tc.contentType, w.Body, codeBodyTooLarge)
+		}
+	}
+}
+
+func TestContactCORSVariesOnOrigin(t *testing.T) {
+	t.Setenv("CONTACT_CORS_ORIGINS", "https://partner.example")
+	r := gin.New()
+	api := r.Group("/api", ContactCORS())
+	api.GET("/openapi.yaml", ContactOpenAPI)
+	api.OPTIONS("/contact", func(c *gin.Context) {})
+
+	for _, tc := range []struct {
+		method, path, origin string
+		allowed              bool
+	}{
+		{http.MethodGet, "/api/openapi.yaml", "", false},
+		{http.MethodGet, "/api/openapi.yaml", "https://elsewhere.example", false},
+		{http.MethodGet, "/api/openapi.yaml", "https://partner.example", true},
+		{http.MethodOptions, "/api/contact", "https://partner.example", true},
+		{http.MethodOptions, "/api/contact", "https://elsewhere.example", false},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		r.ServeHTTP(w, req)
+		if v := w.Header().Values("Vary"); len(v) != 1 || v[0] != "Origin" {
+			t.Errorf("%s %s from %q: Vary %q, want Origin", tc.method, tc.path, tc.origin, v)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin") != ""; got != tc.allowed {
+			t.Errorf("%s %s from %q: CORS allowed = %v, want %v", tc.method, tc.path, tc.origin, got, tc.allowed)
+		}
+	}
+}
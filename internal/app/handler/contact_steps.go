@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/izinga/robustest-web/internal/app/leads"
+	"github.com/izinga/robustest-web/internal/app/views/components"
+)
+
+// contactStepOf maps each submitted field to the step of the contact form
+// that asks for it (1 to components.ContactSteps). Earlier steps post to
+// /api/contact/step, which validates only that step and answers with the
+// next one; the last step posts the whole submission to /api/contact.
+var contactStepOf = map[string]int{
+	"name":         1,
+	"email":        1,
+	"company":      1,
+	"phone":        1,
+	"device_count": 2,
+	"platforms":    2,
+	"frameworks":   2,
+	"deployment":   3,
+	"timeline":     3,
+	"message":      3,
+	"slot":         3,
+}
+
+// firstErrorStep returns the earliest step with an error in errs, so a
+// rejected final submission reopens the form where the problem is.
+func firstErrorStep(errs map[string]string) int {
+	step := components.ContactSteps
+	for field := range errs {
+		if s, ok := contactStepOf[field]; ok && s < step {
+			step = s
+		}
+	}
+	return step
+}
+
+// qualification collects the request's qualification answers.
+func (req ContactFormRequest) qualification() leads.Qualification {
+	q := leads.Qualification{
+		DeviceCount: req.DeviceCount,
+		Platforms:   req.Platforms,
+		Frameworks:  req.Frameworks,
+		Deployment:  req.Deployment,
+		Timeline:    req.Timeline,
+	}
+	if q.DeviceCount > 0 {
+		q.Tier = leads.TierFor(q.DeviceCount).Name
+	}
+	return q
+}
+
+// validateQualification checks the qualification answers against the
+// allowed options. With required set (the htmx form) every question but
+// frameworks must be answered; API clients may leave them all out.
+func (req *ContactFormRequest) validateQualification(required bool) map[string]string {
+	req.Deployment = strings.TrimSpace(req.Deployment)
+	req.Timeline = strings.TrimSpace(req.Timeline)
+	fields := make(map[string]string)
+
+	if required && req.DeviceCount < 1 {
+		fields["device_count"] = "Enter how many devices you plan to connect."
+	}
+	for _, p := range req.Platforms {
+		if !leads.ValidOption(leads.PlatformOptions, p) {
+			fields["platforms"] = "Choose from the listed platforms."
+		}
+	}
+	if required && len(req.Platforms) == 0 {
+		fields["platforms"] = "Pick at least one platform."
+	}
+	for _, f := range req.Frameworks {
+		if !leads.ValidOption(leads.FrameworkOptions, f) {
+			fields["frameworks"] = "Choose from the listed frameworks."
+		}
+	}
+	switch {
+	case req.Deployment != "" && !leads.ValidOption(leads.DeploymentOptions, req.Deployment):
+		fields["deployment"] = "Choose one of the deployment models."
+	case required && req.Deployment == "":
+		fields["deployment"] = "Choose a deployment model."
+	}
+	switch {
+	case req.Timeline != "" && !leads.ValidOption(leads.TimelineOptions, req.Timeline):
+		fields["timeline"] = "Choose one of the timelines."
+	case required && req.Timeline == "":
+		fields["timeline"] = "Choose a timeline."
+	}
+	return fields
+}
+
+// stepErrors validates the fields belonging to one step of the form post.
+// Later steps' fields are still empty at that point and are not reported.
+func (req *ContactFormRequest) stepErrors(step int, bindErr error) map[string]string {
+	all := make(map[string]string)
+	if bindErr != nil {
+		msgs := validationMessages(bindErr, false)
+		var verrs validator.ValidationErrors
+		if msgs == nil && !errors.As(bindErr, &verrs) {
+			// The only field a form post can fail to parse is the number.
+			msgs = map[string]string{"device_count": "Enter a whole number."}
+		}
+		for k, v := range msgs {
+			all[k] = v
+		}
+	}
+	add := func(fields map[string]string) {
+		for k, v := range fields {
+			if _, ok := all[k]; !ok {
+				all[k] = v
+			}
+		}
+	}
+	if err := req.sanitize(); err != nil {
+		add(validationMessages(err, false))
+	}
+	if isDisposableEmail(req.Email) {
+		add(map[string]string{"email": "Use a work email address. Temporary or disposable emails are not accepted."})
+	}
+	add(req.validateQualification(true))
+
+	fields := make(map[string]string)
+	for k, v := range all {
+		if contactStepOf[k] == step {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// qualificationRows lists the qualification answers as label/value pairs
+// for the internal notification email.
+func qualificationRows(q leads.Qualification) [][2]string {
+	var rows [][2]string
+	if q.DeviceCount > 0 {
+		rows = append(rows,
+			[2]string{"Devices", strconv.Itoa(q.DeviceCount)},
+			[2]string{"Indicative size", leads.TierFor(q.DeviceCount).String()})
+	}
+	if labels := leads.Labels(leads.PlatformOptions, q.Platforms); len(labels) > 0 {
+		rows = append(rows, [2]string{"Platforms", strings.Join(labels, ", ")})
+	}
+	if labels := leads.Labels(leads.FrameworkOptions, q.Frameworks); len(labels) > 0 {
+		rows = append(rows, [2]string{"Frameworks", strings.Join(labels, ", ")})
+	}
+	if labels := leads.Labels(leads.DeploymentOptions, []string{q.Deployment}); len(labels) > 0 {
+		rows = append(rows, [2]string{"Deployment", labels[0]})
+	}
+	if labels := leads.Labels(leads.TimelineOptions, []string{q.Timeline}); len(labels) > 0 {
+		rows = append(rows, [2]string{"Timeline", labels[0]})
+	}
+	return rows
+}
+
+// ContactFormStep validates one step of the contact form and renders the
+// next one, or the same step with its errors. A "Back" press (nav=back)
+// returns to the previous step without validating.
+func ContactFormStep(c *gin.Context) {
+	var req ContactFormRequest
+	bindErr := c.ShouldBind(&req)
+
+	step, _ := strconv.Atoi(c.PostForm("step"))
+	if step < 1 || step > components.ContactSteps {
+		step = 1
+	}
+
+	if c.PostForm("nav") == "back" {
+		_ = req.sanitize()
+		renderContactStep(c, http.StatusOK, req, max(step-1, 1), nil)
+		return
+	}
+
+	if fields := req.stepErrors(step, bindErr); len(fields) > 0 {
+		renderContactStep(c, http.StatusBadRequest, req, step, fields)
+		return
+	}
+	renderContactStep(c, http.StatusOK, req, min(step+1, components.ContactSteps), nil)
+}
+
+// renderContactStep renders the form at step with the values so far.
+func renderContactStep(c *gin.Context, status int, req ContactFormRequest, step int, errs map[string]string) {
+	v := req.formValues(errs)
+	v.Step = step
+	c.Status(status)
+	if err := components.ContactForm(v).Render(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("Error rendering contact form step %d: %v", step, err)
+	}
+}
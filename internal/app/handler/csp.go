@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gin-gonic/gin"
+)
+
+// CSP modes (CSP_MODE).
+const (
+	cspEnforce    = "enforce"
+	cspReportOnly = "report-only"
+	cspOff        = "off"
+)
+
+// cspMode is how the Content-Security-Policy is sent (set by InitCSP).
+var cspMode = cspEnforce
+
+// cspNoncePlaceholder stands in for the nonce in pages rendered ahead of
+// any request (the page cache); Cached swaps in the request's nonce.
+const cspNoncePlaceholder = "cspNoncePlaceholder0000"
+
+// cspNonceBytes is the nonce's entropy; every nonce is cspNonceLen
+// characters long, the slot the page cache leaves for it.
+const cspNonceBytes = 16
+
+var cspNonceLen = base64.RawURLEncoding.EncodedLen(cspNonceBytes)
+
+// turnstilePages load Cloudflare Turnstile, the only third party allowed
+// to run scripts or frames on just some pages.
+var turnstilePages = map[string]bool{
+	"/contact":         true,
+	"/privacy/request": true,
+}
+
+// InitCSP reads CSP_MODE: "enforce" (default), "report-only" to only
+// collect violations at /csp-report, or "off".
+func InitCSP() {
+	switch v := os.Getenv("CSP_MODE"); v {
+	case "", cspEnforce:
+		cspMode = cspEnforce
+	case cspReportOnly, cspOff:
+		cspMode = v
+	default:
+		log.Printf("Warning: invalid CSP_MODE %q, using %s", v, cspEnforce)
+		cspMode = cspEnforce
+	}
+}
+
+// ContentSecurityPolicy gives each request a fresh nonce, carried to the
+// templates by templ.WithNonce so every inline <script> is rendered with
+// it, and sends the route's policy allowing only scripts from our origin,
+// Plausible and (on turnstilePages) Cloudflare, plus those nonced scripts.
+// GoatCounter needs no exception: its script is one of our assets and it
+// counts through the /gc proxy.
+// Register it after ConditionalGET: a 304 must not carry a policy whose
+// nonce doesn't match the body the browser already has.
+func ContentSecurityPolicy() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cspMode == cspOff {
+			c.Next()
+			return
+		}
+		nonce, err := newNonce()
+		if err != nil {
+			log.Printf("Error generating CSP nonce: %v", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Request = c.Request.WithContext(templ.WithNonce(c.Request.Context(), nonce))
+		header := "Content-Security-Policy"
+		if cspMode == cspReportOnly {
+			header = "Content-Security-Policy-Report-Only"
+		}
+		c.Header(header, cspPolicy(nonce, turnstilePages[c.Request.URL.Path]))
+		c.Header("Reporting-Endpoints", `csp="`+siteURL("/csp-report")+`"`)
+		c.Next()
+	}
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, cspNonceBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// cspPolicy builds the policy for a page; turnstile adds Cloudflare's
+// challenge script and frame. Plausible is allowed unless the built-in
+// analytics replaces it.
+func cspPolicy(nonce string, turnstile bool) string {
+	scripts := "'self' 'nonce-" + nonce + "'"
+	connect := "'self'"
+	if !BuiltinAnalytics() {
+		scripts += " https://plausible.io"
+		connect += " https://plausible.io"
+	}
+	frames := "'none'"
+	if turnstile {
+		scripts += " https://challenges.cloudflare.com"
+		frames = "https://challenges.cloudflare.com"
+	}
+	return strings.Join([]string{
+		"default-src 'self'",
+		"script-src " + scripts,
+		// Inline style attributes (honeypot fields) and htmx's indicator
+		// styles; styles can't run code.
+		"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com",
+		"font-src 'self' https://fonts.gstatic.com",
+		"img-src 'self' data:",
+		"connect-src " + connect,
+		"frame-src " + frames,
+		"frame-ancestors 'none'",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"form-action 'self'",
+		"report-uri /csp-report",
+		"report-to csp",
+	}, "; ")
+}
+
+// maxCSPReport caps a violation report body.
+const maxCSPReport = 64 << 10
+
+// cspLogLimit caps violations logged per minute, so a noisy browser
+// extension can't flood the log.
+const cspLogLimit = 60
+
+var cspLog struct {
+	sync.Mutex
+	window time.Time
+	n      int
+}
+
+// CSPReport collects violation reports, both the legacy report-uri form
+// (application/csp-report) and Reporting API batches
+// (application/reports+json), and logs them.
+func CSPReport(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCSPReport))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	type violation struct {
+		DocumentURI        string `json:"document-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		BlockedURI         string `json:"blocked-uri"`
+		// Reporting API field names
+		DocumentURL string `json:"documentURL"`
+		Effective   string `json:"effectiveDirective"`
+		BlockedURL  string `json:"blockedURL"`
+	}
+	var reports []violation
+	var legacy struct {
+		Report violation `json:"csp-report"`
+	}
+	var batch []struct {
+		Type string    `json:"type"`
+		Body violation `json:"body"`
+	}
+	switch {
+	case json.Unmarshal(body, &legacy) == nil && legacy.Report != (violation{}):
+		reports = append(reports, legacy.Report)
+	case json.Unmarshal(body, &batch) == nil:
+		for _, r := range batch {
+			if r.Type == "csp-violation" {
+				reports = append(reports, r.Body)
+			}
+		}
+	default:
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	for _, r := range reports {
+		if !cspLogAllowed() {
+			break
+		}
+		log.Printf("CSP violation: %s blocked %s on %s",
+			clip(first(r.EffectiveDirective, r.Effective, r.ViolatedDirective)),
+			clip(first(r.BlockedURI, r.BlockedURL)),
+			clip(first(r.DocumentURI, r.DocumentURL)))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func cspLogAllowed() bool {
+	cspLog.Lock()
+	defer cspLog.Unlock()
+	if now := time.Now(); now.Sub(cspLog.window) > time.Minute {
+		cspLog.window, cspLog.n = now, 0
+	}
+	cspLog.n++
+	return cspLog.n <= cspLogLimit
+}
+
+// first returns the first non-empty value.
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,28 +1,82 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/izinga/robustest-web/internal/app/docs"
+	"github.com/izinga/robustest-web/internal/app/docsearch"
+	"github.com/izinga/robustest-web/internal/app/indexnow"
 	"github.com/izinga/robustest-web/internal/app/views/pages"
 )
 
 var docsStore *docs.Store
 
-// InitDocs starts the documentation sync loop and enables /docs routes.
+// docsNotifier is told about docs pages that change with a sync (nil
+// unless INDEXNOW_KEY is set).
+var docsNotifier indexnow.Notifier
+
+// indexNowKey proves to IndexNow engines that we own the site; it is
+// served at /<key>.txt.
+var indexNowKey string
+
+// InitDocs starts the documentation sync loop, enables /docs routes and
+// opens the docs search log (DOCS_SEARCH_LOG; InitRetention prunes it).
+// With INDEXNOW_KEY set, doc pages added, edited or removed by a sync are
+// submitted to IndexNow.
 func InitDocs() {
 	docsStore = docs.NewStore()
+	if client := indexnow.FromEnv(siteURL("")); client != nil {
+		docsNotifier = client
+		indexNowKey = client.Key
+	}
+	docsStore.OnChange(notifyDocChanges)
 	docsStore.Start()
+	docsSearches = docsearch.LogFromEnv()
+	initDocsFeedback()
 }
 
-// DocsPage serves rendered documentation pages, synced assets, and the
-// refresh endpoint.
+// notifyDocChanges submits the URLs of changed doc pages.
+func notifyDocChanges(paths []string) {
+	if docsNotifier == nil {
+		return
+	}
+	urls := make([]string, len(paths))
+	for i, p := range paths {
+		urls[i] = siteURL(strings.TrimSuffix("/docs/"+p, "/"))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := docsNotifier.Notify(ctx, urls); err != nil {
+		log.Printf("docs: IndexNow: %v", err)
+		return
+	}
+	log.Printf("docs: IndexNow: submitted %d changed page(s)", len(urls))
+}
+
+// IndexNowKeyPath returns the path of the IndexNow key file, or "" when
+// IndexNow is off. Call it after InitDocs.
+func IndexNowKeyPath() string {
+	if indexNowKey == "" {
+		return ""
+	}
+	return "/" + indexNowKey + ".txt"
+}
+
+// IndexNowKeyFile serves the IndexNow key file.
+func IndexNowKeyFile(c *gin.Context) {
+	c.String(http.StatusOK, indexNowKey)
+}
+
+// DocsPage serves rendered documentation pages, synced assets, the
+// changelog and the refresh endpoint.
 func DocsPage(c *gin.Context) {
 	path := strings.Trim(c.Param("path"), "/")
 
@@ -45,6 +99,18 @@ func DocsPage(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok", "sha": sha, "synced_at": syncedAt})
 		return
 
+	case path == "changes":
+		docsChanges(c, "html")
+		return
+
+	case path == "changes.atom" || path == "changes.json":
+		docsChanges(c, strings.TrimPrefix(path, "changes."))
+		return
+
+	case strings.HasSuffix(path, ".md"):
+		docsMarkdown(c, strings.TrimSuffix(path, ".md"))
+		return
+
 	case strings.HasPrefix(path, "assets/"):
 		dir := docsStore.Dir()
 		if dir == "" {
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/docs"
+)
+
+// fakeNotifier records what would have been submitted to IndexNow.
+type fakeNotifier chan []string
+
+func (n fakeNotifier) Notify(_ context.Context, urls []string) error {
+	n <- urls
+	return nil
+}
+
+// useDocs points docsStore at a new bundled docs dir holding files,
+// restoring the previous store when the test ends. It returns the dir.
+func useDocs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	t.Setenv("DOCS_LOCAL_DIR", dir)
+	t.Setenv("DOCS_DIR", t.TempDir())
+	t.Setenv("SITE_URL", "https://example.test")
+	prev := docsStore
+	t.Cleanup(func() { docsStore = prev })
+	docsStore = docs.NewStore()
+	return dir
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(dir, rel)
+		if content == "" {
+			os.Remove(p)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var testDocs = map[string]string{
+	"_sidebar.md":       "* **Guides**\n  * [Setup](guides/setup.md)\n  * [Devices](guides/devices.md)\n",
+	"README.md":         "# Docs\n",
+	"guides/setup.md":   "# Setup\n\n## Install\n",
+	"guides/devices.md": "# Devices\n",
+	".commit-dates.tsv": "README.md\t2026-01-05T10:00:00Z\tAda\n" +
+		"guides/setup.md\t2026-03-01T10:00:00Z\tAda\n" +
+		"guides/devices.md\t2026-02-01T10:00:00Z\tGrace\n",
+}
+
+func TestDocsSyncNotifiesIndexNow(t *testing.T) {
+	dir := useDocs(t, testDocs)
+	notified := make(fakeNotifier, 1)
+	prev := docsNotifier
+	docsNotifier = notified
+	t.Cleanup(func() { docsNotifier = prev })
+	docsStore.OnChange(notifyDocChanges)
+
+	if err := docsStore.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case urls := <-notified:
+		t.Fatalf("first sync submitted %q", urls)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The next tree edits the home page and one guide, drops another and
+	// adds a third.
+	writeFiles(t, dir, map[string]string{
+		"_sidebar.md":       "* **Guides**\n  * [Setup](guides/setup.md)\n  * [Grids](guides/grids.md)\n",
+		"README.md":         "# Docs\n\nWelcome.\n",
+		"guides/setup.md":   "# Setup\n\n## Install\n\n## Log in\n",
+		"guides/devices.md": "",
+		"guides/grids.md":   "# Grids\n",
+	})
+	if err := docsStore.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case urls := <-notified:
+		want := []string{
+			"https://example.test/docs",
+			"https://example.test/docs/guides/devices",
+			"https://example.test/docs/guides/grids",
+			"https://example.test/docs/guides/setup",
+		}
+		if !reflect.DeepEqual(urls, want) {
+			t.Errorf("submitted %q, want %q", urls, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second sync submitted nothing")
+	}
+}
+
+func serveSitemap(h gin.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+	h(c)
+	return w
+}
+
+func TestSitemapIndex(t *testing.T) {
+	useDocs(t, testDocs)
+	if err := docsStore.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := serveSitemap(SitemapXML)
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	index := w.Body.String()
+	if !strings.Contains(index, "<sitemapindex") || strings.Count(index, "<sitemap>") != 2 {
+		t.Fatalf("not an index of two sitemaps:\n%s", index)
+	}
+	for _, want := range []string{
+		"<loc>https://robustest.com/sitemaps/pages.xml</loc>",
+		// The docs sitemap is dated by its newest page.
+		"<loc>https://robustest.com/sitemaps/docs.xml</loc>\n    <lastmod>2026-03-01</lastmod>",
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index lacks %q:\n%s", want, index)
+		}
+	}
+
+	docsMap := serveSitemap(SitemapDocs).Body.String()
+	for _, want := range []string{
+		"<loc>https://robustest.com/docs/guides/setup</loc>\n    <lastmod>2026-03-01</lastmod>",
+		"<loc>https://robustest.com/docs/guides/devices</loc>\n    <lastmod>2026-02-01</lastmod>",
+	} {
+		if !strings.Contains(docsMap, want) {
+			t.Errorf("docs sitemap lacks %q:\n%s", want, docsMap)
+		}
+	}
+	if strings.Count(docsMap, "<url>") != 2 {
+		t.Errorf("docs sitemap should list the two guides only (the home is a registered page):\n%s", docsMap)
+	}
+
+	pagesMap := serveSitemap(SitemapPages).Body.String()
+	for _, want := range []string{
+		"<loc>https://robustest.com/pricing</loc>",
+		"<loc>https://robustest.com/docs</loc>\n    <lastmod>2026-01-05</lastmod>",
+		`hreflang="x-default" href="https://robustest.com/pricing"`,
+	} {
+		if !strings.Contains(pagesMap, want) {
+			t.Errorf("pages sitemap lacks %q", want)
+		}
+	}
+	if strings.Contains(pagesMap, "/docs/guides/") {
+		t.Error("pages sitemap lists doc pages")
+	}
+}
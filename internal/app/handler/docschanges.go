@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/xml"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/docs"
+	"github.com/izinga/robustest-web/internal/app/views/pages"
+)
+
+// maxDocsChanges is how many syncs the changelog page and feeds list.
+const maxDocsChanges = 50
+
+// docsChanges serves the docs changelog (what each sync added, edited and
+// removed) at /docs/changes, as HTML, Atom (changes.atom) or JSON
+// (changes.json).
+func docsChanges(c *gin.Context, format string) {
+	if !docsStore.Ready() {
+		c.Status(http.StatusServiceUnavailable)
+		return
+	}
+	changes, err := docsStore.Changes(maxDocsChanges)
+	if err != nil {
+		log.Printf("Error reading docs changelog: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	switch format {
+	case "json":
+		if changes == nil {
+			changes = []docs.Change{}
+		}
+		c.JSON(http.StatusOK, changes)
+	case "atom":
+		raw, err := xml.MarshalIndent(docsChangesFeed(changes), "", "  ")
+		if err != nil {
+			log.Printf("Error rendering docs changelog feed: %v", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", append([]byte(xml.Header), raw...))
+	default:
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := pages.DocsChanges(changes, docsStore.Nav()).Render(c.Request.Context(), c.Writer); err != nil {
+			log.Printf("Error rendering docs changelog: %v", err)
+		}
+	}
+}
+
+// atomFeed and the types below are the parts of RFC 4287 the changelog
+// feed uses.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Content atomText `xml:"content"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// docsChangesFeed is the Atom feed of changes, one entry per sync.
+func docsChangesFeed(changes []docs.Change) atomFeed {
+	feed := atomFeed{
+		ID:     siteURL("/docs/changes"),
+		Title:  "RobusTest documentation changes",
+		Author: atomAuthor{Name: "RobusTest"},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: siteURL("/docs/changes.atom")},
+			{Rel: "alternate", Type: "text/html", Href: siteURL("/docs/changes")},
+		},
+	}
+	if len(changes) > 0 {
+		feed.Updated = changes[0].Time.UTC().Format(time.RFC3339)
+	} else {
+		_, at := docsStore.Published()
+		feed.Updated = at.UTC().Format(time.RFC3339)
+	}
+	for _, ch := range changes {
+		var b strings.Builder
+		for _, group := range []struct {
+			label string
+			pages []docs.ChangedPage
+		}{{"Updated", ch.Modified}, {"Added", ch.Added}, {"Removed", ch.Removed}} {
+			for _, p := range group.pages {
+				b.WriteString(group.label + ": " + p.Title + " (" + siteURL(strings.TrimSuffix("/docs/"+p.Path, "/")) + ")\n")
+			}
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      siteURL("/docs/changes#" + ch.SHA),
+			Title:   ch.Summary(),
+			Updated: ch.Time.UTC().Format(time.RFC3339),
+			Link:    atomLink{Rel: "alternate", Type: "text/html", Href: siteURL("/docs/changes#" + ch.SHA)},
+			Content: atomText{Type: "text", Body: b.String()},
+		})
+	}
+	return feed
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/docsearch"
+	"github.com/izinga/robustest-web/internal/app/views/pages"
+)
+
+// docsSearches records docs searches reported by docs.js (set by InitDocs).
+var docsSearches *docsearch.Log
+
+// docsSearchRateLimiter caps search beacons per address; a reader typing
+// sends one per pause.
+var docsSearchRateLimiter = &rateLimiter{
+	requests: make(map[string][]time.Time),
+	limit:    60,
+	window:   time.Minute,
+}
+
+// Limits on a search beacon.
+const (
+	maxSearchBeacon = 2 << 10
+	maxSearchQuery  = 100
+)
+
+// DocsSearchLog records a docs search (POST /docs/search-log): the query,
+// how many results docs.js found and the result opened, if any, against
+// the docs SHA currently published. It always answers 204.
+func DocsSearchLog(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+	if docsSearches == nil || docsStore == nil || !docsStore.Ready() || !docsSearchRateLimiter.isAllowed(c.ClientIP()) {
+		return
+	}
+	var beacon struct {
+		Query   string `json:"q"`
+		Results int    `json:"n"`
+		Clicked string `json:"clicked"`
+		Counted bool   `json:"counted"`
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSearchBeacon))
+	if err != nil || json.Unmarshal(body, &beacon) != nil {
+		return
+	}
+	query := strings.Join(strings.Fields(strings.ToLower(beacon.Query)), " ")
+	if len(query) < 2 || beacon.Results < 0 {
+		return
+	}
+	if len(query) > maxSearchQuery {
+		// Cut on a rune boundary, so the log holds valid UTF-8.
+		n := maxSearchQuery
+		for n > 0 && !utf8.RuneStart(query[n]) {
+			n--
+		}
+		query = strings.TrimSpace(query[:n])
+	}
+	clicked := beacon.Clicked
+	if clicked != "" && !docsIndexed(clicked) {
+		return
+	}
+	sha, _ := docsStore.Published()
+	err = docsSearches.Append(docsearch.Entry{
+		Time:    time.Now().UTC(),
+		SHA:     sha,
+		Query:   query,
+		Results: beacon.Results,
+		Clicked: clicked,
+		Counted: beacon.Counted && clicked != "",
+	})
+	if err != nil {
+		log.Printf("Error recording docs search: %v", err)
+	}
+}
+
+// docsIndexed reports whether urlPath ("/docs/intro") is a page in the
+// docs search index.
+func docsIndexed(urlPath string) bool {
+	for _, e := range docsStore.Index() {
+		if urlPath == strings.TrimSuffix("/docs/"+e.Path, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// DocsSearchReport lists, for one docs SHA (?sha=, default the one
+// published now), the top queries, the queries that found nothing and
+// those whose results readers don't open.
+func DocsSearchReport(c *gin.Context) {
+	all, err := docsSearches.All()
+	if err != nil {
+		log.Printf("Error reading docs searches: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	shas := docsearch.SHAs(all)
+	sha := c.Query("sha")
+	if sha == "" && docsStore != nil {
+		sha, _ = docsStore.Published()
+	}
+	if sha == "" && len(shas) > 0 {
+		sha = shas[0].SHA
+	}
+	days := reportDays(c)
+	report := docsearch.Summarize(all, sha, time.Now().AddDate(0, 0, -days), 50)
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{"days": days, "report": report, "shas": shas})
+		return
+	}
+	renderPage(c, "docs searches", func() error {
+		return pages.AdminDocsSearches(report, shas, days).Render(c.Request.Context(), c.Writer)
+	})
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/docsearch"
+)
+
+// useSearchLog points docsSearches at a new log for the test.
+func useSearchLog(t *testing.T) *docsearch.Log {
+	l := docsearch.NewLog(filepath.Join(t.TempDir(), "docs-searches.jsonl"))
+	prev := docsSearches
+	docsSearches = l
+	t.Cleanup(func() { docsSearches = prev })
+	return l
+}
+
+func postSearch(ip, body string) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/docs/search-log", strings.NewReader(body))
+	c.Request.RemoteAddr = ip + ":1234"
+	DocsSearchLog(c)
+}
+
+func TestDocsSearchLogTruncatesOnRunes(t *testing.T) {
+	useDocs(t, testDocs)
+	if err := docsStore.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	l := useSearchLog(t)
+
+	// The cut at maxSearchQuery bytes lands inside the first ü.
+	query := strings.Repeat("a", maxSearchQuery-1) + strings.Repeat("ü", 10)
+	postSearch("192.0.2.10", `{"q":"`+query+`","n":1}`)
+
+	all, err := l.All()
+	if err != nil || len(all) != 1 {
+		t.Fatalf("logged %v, %v; want one search", all, err)
+	}
+	got := all[0].Query
+	if !utf8.ValidString(got) || got != strings.Repeat("a", maxSearchQuery-1) {
+		t.Errorf("logged query %q (%d bytes), want the %d a's before the split rune", got, len(got), maxSearchQuery-1)
+	}
+}
+
+func TestDocsSearchLogRateLimit(t *testing.T) {
+	useDocs(t, testDocs)
+	if err := docsStore.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	l := useSearchLog(t)
+
+	for range docsSearchRateLimiter.limit + 5 {
+		postSearch("192.0.2.11", `{"q":"setup","n":1}`)
+	}
+	postSearch("192.0.2.12", `{"q":"setup","n":1}`)
+	all, _ := l.All()
+	if len(all) != docsSearchRateLimiter.limit+1 {
+		t.Errorf("logged %d searches, want %d from the flooding address and 1 from another", len(all), docsSearchRateLimiter.limit)
+	}
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/a-h/templ"
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/docs"
+	"github.com/izinga/robustest-web/internal/app/feedback"
+	"github.com/izinga/robustest-web/internal/app/views/pages"
+)
+
+// docsFeedback stores "Was this page helpful?" answers (set by InitDocs).
+var docsFeedback *feedback.Store
+
+// feedbackNotifiers receive comments left on docs pages: Slack
+// (DOCS_FEEDBACK_SLACK_WEBHOOK) and email (DOCS_FEEDBACK_EMAIL).
+var feedbackNotifiers []feedback.Notifier
+
+// feedbackRateLimiter throttles /docs/feedback per address.
+var feedbackRateLimiter = &rateLimiter{
+	requests: make(map[string][]time.Time),
+	limit:    10,
+	window:   10 * time.Minute,
+}
+
+// maxFeedbackComment caps a comment, in characters.
+const maxFeedbackComment = 1000
+
+// initDocsFeedback opens the feedback store and sets up the notifiers.
+func initDocsFeedback() {
+	docsFeedback = feedback.StoreFromEnv()
+	feedbackNotifiers = nil
+	if s := feedback.SlackFromEnv(siteURL("")); s != nil {
+		feedbackNotifiers = append(feedbackNotifiers, s)
+	}
+	if to := os.Getenv("DOCS_FEEDBACK_EMAIL"); to != "" {
+		feedbackNotifiers = append(feedbackNotifiers, emailFeedback{to: to})
+	}
+}
+
+// emailFeedback emails comments to the docs team through SendGrid.
+type emailFeedback struct{ to string }
+
+// Name implements feedback.Notifier.
+func (e emailFeedback) Name() string { return "email" }
+
+// Notify implements feedback.Notifier.
+func (e emailFeedback) Notify(ctx context.Context, f feedback.Feedback) error {
+	link := f.Page
+	if f.Anchor != "" {
+		link += "#" + f.Anchor
+	}
+	htmlContent, textContent := buildNoticeEmail("", []string{
+		feedback.Summary(f) + ":",
+		f.Comment,
+	}, [][2]string{{"Open the page", siteURL(link)}})
+	return sendEmail(e.to, feedback.Summary(f), htmlContent, textContent)
+}
+
+// DocsFeedback takes the answers of the feedback widget on docs pages. A
+// vote (page, helpful=yes|no) is stored and answered with the comment
+// form; the comment (id, comment, anchor) is added to the vote and passed
+// to the notifiers. The contact form's honeypot, rate limiting and spam
+// patterns apply; caught submissions get the usual thanks. Without htmx,
+// the reader is sent back to the page.
+func DocsFeedback(c *gin.Context) {
+	if c.PostForm("website") != "" {
+		log.Printf("Honeypot triggered on docs feedback from IP: %s", c.ClientIP())
+		feedbackRespond(c, c.PostForm("page"), pages.DocsFeedbackThanks())
+		return
+	}
+	if !feedbackRateLimiter.isAllowed(c.ClientIP()) {
+		feedbackRespond(c, c.PostForm("page"), pages.DocsFeedbackMessage("Too many answers from your network. Please try again later."))
+		return
+	}
+	if docsStore == nil || !docsStore.Ready() {
+		c.Status(http.StatusServiceUnavailable)
+		return
+	}
+	if id := c.PostForm("id"); id != "" {
+		docsFeedbackComment(c, id)
+		return
+	}
+
+	page := c.PostForm("page")
+	helpful := c.PostForm("helpful")
+	if !docsIndexed(page) || (helpful != "yes" && helpful != "no") {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	sha, _ := docsStore.Published()
+	f := feedback.New(page, sha, helpful == "yes")
+	if err := docsFeedback.Append(f); err != nil {
+		log.Printf("Error storing docs feedback: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	feedbackRespond(c, page, pages.DocsFeedbackComment(f.ID, f.Helpful, docsTOC(page)))
+}
+
+// docsFeedbackComment adds the comment to the vote with id, once.
+func docsFeedbackComment(c *gin.Context, id string) {
+	f, ok, err := docsFeedback.Get(id)
+	if err != nil {
+		log.Printf("Error reading docs feedback: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	comment := strings.TrimSpace(c.PostForm("comment"))
+	if !ok || f.Comment != "" || comment == "" {
+		feedbackRespond(c, f.Page, pages.DocsFeedbackThanks())
+		return
+	}
+	if utf8.RuneCountInString(comment) > maxFeedbackComment {
+		comment = string([]rune(comment)[:maxFeedbackComment])
+	}
+	if containsSpamContent("", comment) {
+		log.Printf("Spam content in docs feedback from IP: %s", c.ClientIP())
+		feedbackRespond(c, f.Page, pages.DocsFeedbackThanks())
+		return
+	}
+	f.Comment = comment
+	for _, item := range docsTOC(f.Page) {
+		if item.ID == c.PostForm("anchor") {
+			f.Anchor = item.ID
+		}
+	}
+	if err := docsFeedback.Append(f); err != nil {
+		log.Printf("Error storing docs feedback: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	for _, n := range feedbackNotifiers {
+		go func(n feedback.Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := n.Notify(ctx, f); err != nil {
+				log.Printf("docs feedback: %s: %v", n.Name(), err)
+			}
+		}(n)
+	}
+	feedbackRespond(c, f.Page, pages.DocsFeedbackThanks())
+}
+
+// docsTOC returns the headings of the docs page at urlPath ("/docs/x").
+func docsTOC(urlPath string) []docs.TOCItem {
+	page, err := docsStore.Load(strings.TrimPrefix(urlPath, "/docs"))
+	if err != nil {
+		return nil
+	}
+	return page.TOC
+}
+
+// feedbackRespond renders the widget's next state for htmx, and sends
+// other clients back to the page they rated.
+func feedbackRespond(c *gin.Context, page string, next templ.Component) {
+	if c.GetHeader("HX-Request") == "" {
+		if !strings.HasPrefix(page, "/docs") {
+			page = "/docs"
+		}
+		c.Redirect(http.StatusSeeOther, page+"#docs-feedback")
+		return
+	}
+	renderPage(c, "docs feedback", func() error {
+		return next.Render(c.Request.Context(), c.Writer)
+	})
+}
+
+// DocsFeedbackReport lists the votes and comments on docs pages by page and
+// docs SHA, least helpful first.
+func DocsFeedbackReport(c *gin.Context) {
+	all, err := docsFeedback.All()
+	if err != nil {
+		log.Printf("Error reading docs feedback: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	days := reportDays(c)
+	rows := feedback.Summarize(all, time.Now().AddDate(0, 0, -days))
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{"days": days, "pages": rows})
+		return
+	}
+	renderPage(c, "docs feedback", func() error {
+		return pages.AdminDocsFeedback(rows, days).Render(c.Request.Context(), c.Writer)
+	})
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/feedback"
+)
+
+// fakeFeedbackNotifier passes on the comments it is given.
+type fakeFeedbackNotifier chan feedback.Feedback
+
+func (n fakeFeedbackNotifier) Name() string { return "fake" }
+
+func (n fakeFeedbackNotifier) Notify(_ context.Context, f feedback.Feedback) error {
+	n <- f
+	return nil
+}
+
+// useFeedback points docsFeedback at a new store and the notifiers at one
+// fake for the test.
+func useFeedback(t *testing.T) (*feedback.Store, fakeFeedbackNotifier) {
+	s := feedback.NewStore(filepath.Join(t.TempDir(), "docs-feedback.jsonl"))
+	n := make(fakeFeedbackNotifier, 4)
+	prevStore, prevNotifiers := docsFeedback, feedbackNotifiers
+	docsFeedback, feedbackNotifiers = s, []feedback.Notifier{n}
+	t.Cleanup(func() { docsFeedback, feedbackNotifiers = prevStore, prevNotifiers })
+	return s, n
+}
+
+// postFeedback posts form to DocsFeedback from ip and returns the status.
+func postFeedback(ip string, form url.Values) int {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/docs/feedback", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Request.RemoteAddr = ip + ":1234"
+	DocsFeedback(c)
+	return c.Writer.Status()
+}
+
+func TestDocsFeedbackCommentsOnce(t *testing.T) {
+	useDocs(t, testDocs)
+	if err := docsStore.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	store, notified := useFeedback(t)
+
+	if status := postFeedback("192.0.2.20", url.Values{"page": {"/docs/guides/setup"}, "helpful": {"no"}}); status != http.StatusSeeOther {
+		t.Fatalf("vote: status %d", status)
+	}
+	all, err := store.All()
+	if err != nil || len(all) != 1 || all[0].Helpful {
+		t.Fatalf("after the vote: %+v, %v", all, err)
+	}
+	id := all[0].ID
+
+	for i, tc := range []struct {
+		id, comment, anchor string
+		wantComment         string
+	}{
+		{"nope", "On an unknown vote", "", ""},
+		{id, "   ", "", ""}, // blank: the vote can still take a comment
+		{id, "The install step fails on macOS.", "install", "The install step fails on macOS."},
+		{id, "And a second thought", "", "The install step fails on macOS."}, // once only
+	} {
+		form := url.Values{"id": {tc.id}, "comment": {tc.comment}, "anchor": {tc.anchor}}
+		// One address per post, clear of the rate limit.
+		postFeedback(fmt.Sprintf("192.0.2.%d", 21+i), form)
+		got, _, err := store.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Comment != tc.wantComment {
+			t.Errorf("after comment %q: %q, want %q", tc.comment, got.Comment, tc.wantComment)
+		}
+	}
+
+	got, _, _ := store.Get(id)
+	if got.Anchor != "install" {
+		t.Errorf("anchor = %q, want install", got.Anchor)
+	}
+	select {
+	case f := <-notified:
+		if f.ID != id || f.Comment != "The install step fails on macOS." {
+			t.Errorf("notified %+v", f)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the comment was not passed to the notifiers")
+	}
+	select {
+	case f := <-notified:
+		t.Errorf("notified again: %+v", f)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
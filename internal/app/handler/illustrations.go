@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/views/components"
+)
+
+// illustrations are the product illustrations published as standalone SVG
+// at /illustrations/<slug>.svg, so the image sitemap can list them with the
+// pages that show them inline (site.Page.Images).
+var illustrations = map[string]templ.Component{
+	"live-session": components.IlloLiveSession(),
+	"perf-compare": components.IlloPerfCompare(),
+	"har-view":     components.IlloHarView(),
+	"run-report":   components.IlloRunReport(),
+	"fleet":        components.IlloFleet(),
+	"tv-session":   components.IlloTVSession(),
+	"optical":      components.IlloOptical(),
+	"integrations": components.IlloIntegrations(),
+}
+
+// illustrationPalette is the light theme from src/css/input.css: the
+// illustrations draw with the site's CSS variables, which a standalone
+// image has to define itself.
+const illustrationPalette = `:root{--rt-paper:#f4f7f9;--rt-surface:#fcfdfe;--rt-ink:#10181f;--rt-muted:#46535d;` +
+	`--rt-line:#dbe2e8;--rt-line-strong:#b3bfc9;--rt-signal:#0e7fb5;--rt-signal-soft:#e0f0f8;` +
+	`--rt-trace:#205f92;--rt-trace-soft:#e4edf6;--rt-amber:#b45309;--rt-amber-soft:#f6ead9}`
+
+// illustrationURL is the absolute URL of the illustration slug.
+func illustrationURL(slug string) string {
+	return "https://robustest.com/illustrations/" + slug + ".svg"
+}
+
+// Illustration serves one illustration as an SVG document.
+func Illustration(c *gin.Context) {
+	slug, ok := strings.CutSuffix(c.Param("file"), ".svg")
+	illo, found := illustrations[slug]
+	if !ok || !found {
+		NotFoundPage(c)
+		return
+	}
+	c.Header("Content-Type", "image/svg+xml; charset=utf-8")
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Status(http.StatusOK)
+	c.Writer.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="800" height="400" viewBox="0 0 800 400"><style>` + illustrationPalette + `</style>`)
+	if err := illo.Render(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("Error rendering illustration %s: %v", slug, err)
+		return
+	}
+	c.Writer.WriteString("</svg>\n")
+}
@@ -1,20 +1,23 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/docs"
+	"github.com/izinga/robustest-web/internal/app/site"
 )
 
-// LlmsTxt serves /llms.txt (llmstxt.org): a curated markdown map of the
-// site for AI assistants and crawlers, so answers about RobusTest are
-// drawn from authoritative summaries rather than scraped fragments.
-func LlmsTxt(c *gin.Context) {
-	var b strings.Builder
-
-	b.WriteString(`# RobusTest
+// llmsIntro opens both llms.txt and llms-full.txt.
+const llmsIntro = `# RobusTest
 
 > RobusTest is an enterprise on-premise device lab platform by Izinga Software
 > (Hyderabad, India, since 2014). Companies rack their own phones, tablets,
@@ -34,54 +37,219 @@ capture records HTTP(S) traffic as HAR on every session. RobusTest is
 sales-led and fully managed (hardware, software, and support delivered);
 the same team's self-serve SaaS alternative is DeviceLab (devicelab.dev).
 
-## Main pages
+`
 
-`)
-	pageDescs := []struct{ path, title, desc string }{
-		{"/", "Home", "What RobusTest is: the enterprise device lab on your premises"},
-		{"/features", "Platform overview", "All seven capabilities and how they fit together"},
-		{"/platform/manual-testing", "Manual testing", "Live device control from the browser: touch, GPS, shell, logs, vitals"},
-		{"/platform/test-automation", "Test automation", "Appium hub, Espresso, XCUITest, Selenium grid, parallel runs, CI API"},
-		{"/platform/performance-testing", "Performance testing", "No-SDK vitals on every session; build-over-build regression statistics"},
-		{"/platform/tv-testing", "Smart TV & OTT testing", "Tizen, webOS, Roku, Apple TV, Android/Fire TV, consoles; model-year support matrix"},
-		{"/platform/network-capture", "Network capture", "Automatic HAR capture, HTTPS inspection, gRPC/protobuf decoding, mocking"},
-		{"/platform/device-lab", "Device lab operations", "Health scoring, booking, smart power control, iOS MDM, build library"},
-		{"/platform/integrations", "Integrations & enterprise", "Google/Microsoft SSO, JIRA, ReportPortal, Slack, InfluxDB, CI/CD API"},
-		{"/enterprise", "For enterprise teams", "Industry scenarios: streaming/OTT, banking & fintech, telecom"},
-		{"/partners", "For testing services companies", "Partner model: run RobusTest labs at your site, deliver testing to clients"},
-		{"/pricing", "Pricing", "Flat yearly license by device count; unlimited users and minutes; hardware included"},
-		{"/security", "Security", "On-premise architecture, data ownership policy, air-gap, SSO, device certificates"},
-		{"/about", "About", "Izinga Software, Hyderabad; building device labs since 2014"},
-		{"/contact", "Contact", "Book a demo or request a lab quote"},
-	}
-	for _, p := range pageDescs {
-		fmt.Fprintf(&b, "- [%s](https://robustest.com%s): %s\n", p.title, p.path, p.desc)
-	}
+// LlmsTxt serves /llms.txt (llmstxt.org): a curated markdown map of the
+// site for AI assistants and crawlers, so answers about RobusTest are
+// drawn from authoritative summaries rather than scraped fragments.
+func LlmsTxt(c *gin.Context) {
+	serveText(c, "llms.txt", "text/plain; charset=utf-8", func() (string, error) {
+		var b strings.Builder
+		b.WriteString(llmsIntro)
+		b.WriteString("Every page below also has a Markdown version at its URL plus `.md`\n")
+		b.WriteString("(`/index.md` for the home page), and https://robustest.com/llms-full.txt\n")
+		b.WriteString("holds all of it in one file.\n\n## Main pages\n\n")
+		for _, p := range site.Pages() {
+			fmt.Fprintf(&b, "- [%s](https://robustest.com%s): %s\n", p.Name, p.Path, p.Summary)
+		}
 
-	if docsStore != nil && docsStore.Ready() {
-		b.WriteString("\n## Documentation\n\n")
-		b.WriteString("Product documentation for RobusTest users:\n\n")
-		section := ""
-		for _, entry := range docsStore.Index() {
-			if entry.Path == "" {
-				continue
-			}
-			if entry.Section != section {
-				section = entry.Section
-				fmt.Fprintf(&b, "\n### %s\n\n", section)
+		if docsStore != nil && docsStore.Ready() {
+			b.WriteString("\n## Documentation\n\n")
+			b.WriteString("Product documentation for RobusTest users:\n\n")
+			section := ""
+			for _, entry := range docsStore.Index() {
+				if entry.Path == "" {
+					continue
+				}
+				if entry.Section != section {
+					section = entry.Section
+					fmt.Fprintf(&b, "\n### %s\n\n", section)
+				}
+				fmt.Fprintf(&b, "- [%s](https://robustest.com/docs/%s.md)\n", entry.Title, entry.Path)
 			}
-			fmt.Fprintf(&b, "- [%s](https://robustest.com/docs/%s)\n", entry.Title, entry.Path)
 		}
-	}
 
-	b.WriteString(`
+		b.WriteString(`
 ## Optional
 
 - [maestro-runner](https://github.com/devicelab-dev/maestro-runner): open-source Maestro alternative by the same team (Apache 2.0)
 - [DeviceLab](https://devicelab.dev): the team's self-serve SaaS device lab (software only, you run it yourself)
 `)
+		return b.String(), nil
+	})
+}
+
+// LlmsFullTxt serves /llms-full.txt: the llms.txt introduction, a summary
+// of every page, then the full docs in sidebar order.
+func LlmsFullTxt(c *gin.Context) {
+	serveText(c, "llms-full.txt", "text/plain; charset=utf-8", func() (string, error) {
+		var b strings.Builder
+		b.WriteString(llmsIntro)
+		for _, p := range site.Pages() {
+			if p.Path == "/docs" {
+				continue // the docs follow in full
+			}
+			b.WriteString(pageSummary(p))
+			b.WriteString("\n")
+		}
+		if docsStore == nil || !docsStore.Ready() {
+			return b.String(), nil
+		}
+		seen := map[string]bool{}
+		for _, entry := range append([]docs.IndexEntry{{Path: ""}}, docsStore.Index()...) {
+			if seen[entry.Path] {
+				continue
+			}
+			seen[entry.Path] = true
+			page, err := docsStore.Load(entry.Path)
+			if err != nil {
+				continue // sidebar link to a missing file
+			}
+			fmt.Fprintf(&b, "---\n\nSource: %s\n\n", siteURL(docURL(entry.Path)))
+			b.WriteString(strings.TrimSpace(page.Markdown))
+			b.WriteString("\n\n")
+		}
+		return b.String(), nil
+	})
+}
+
+// MarkdownPath is the URL of the Markdown version of the registered page at
+// path: /pricing.md, and /index.md for the home page.
+func MarkdownPath(path string) string {
+	if path == "/" {
+		return "/index.md"
+	}
+	return path + ".md"
+}
 
-	c.Header("Content-Type", "text/plain; charset=utf-8")
+// PageMarkdown serves the Markdown version of a registered page (routed at
+// MarkdownPath): a plain-text summary from the page registry. /docs.md is
+// the docs home page itself.
+func PageMarkdown(c *gin.Context) {
+	path := strings.TrimSuffix(c.FullPath(), ".md")
+	if path == "/index" {
+		path = "/"
+	}
+	if path == "/docs" {
+		docsMarkdown(c, "")
+		return
+	}
+	p, ok := site.Lookup(path)
+	if !ok {
+		NotFoundPage(c)
+		return
+	}
+	serveText(c, "md|"+path, "text/markdown; charset=utf-8", func() (string, error) {
+		return pageSummary(p), nil
+	})
+}
+
+// pageSummary renders a registered page as a short Markdown document.
+func pageSummary(p site.Page) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n> %s\n\n%s\n\n", p.Name, p.Summary, p.Description)
+	if trail := site.Breadcrumbs(p.Path); len(trail) > 1 {
+		parent := trail[len(trail)-2]
+		fmt.Fprintf(&b, "Part of: [%s](%s)\n", parent.Name, siteURL(parent.Path))
+	}
+	fmt.Fprintf(&b, "Full page: %s\n", siteURL(p.Path))
+	return b.String()
+}
+
+// docsMarkdown serves the link-normalized Markdown source of the doc page
+// at urlPath.
+func docsMarkdown(c *gin.Context, urlPath string) {
+	if urlPath == "README" || urlPath == "index" {
+		urlPath = ""
+	}
+	if docsStore == nil || !docsStore.Ready() {
+		c.String(http.StatusServiceUnavailable, "docs not synced\n")
+		return
+	}
+	serveText(c, "docs|"+urlPath, "text/markdown; charset=utf-8", func() (string, error) {
+		page, err := docsStore.Load(urlPath)
+		if err != nil {
+			return "", err
+		}
+		return page.Markdown, nil
+	})
+}
+
+// docURL is the path of the doc page at urlPath ("" is the docs home).
+func docURL(urlPath string) string {
+	if urlPath == "" {
+		return "/docs"
+	}
+	return "/docs/" + urlPath
+}
+
+// cachedText is a generated text response with its strong ETag.
+type cachedText struct {
+	body string
+	etag string
+}
+
+// textCache holds the generated text responses (llms.txt, llms-full.txt
+// and the Markdown variants). They depend only on the build and the synced
+// docs tree, so the cache is dropped whenever the docs SHA changes.
+var textCache struct {
+	mu      sync.Mutex
+	sha     string
+	entries map[string]cachedText
+}
+
+// serveText answers with the cached body under key, building it on a miss,
+// and honours If-None-Match against the body's ETag. A build error that
+// wraps os.ErrNotExist is a 404.
+func serveText(c *gin.Context, key, contentType string, build func() (string, error)) {
+	sha := ""
+	if docsStore != nil {
+		sha, _, _ = docsStore.Status()
+	}
+	textCache.mu.Lock()
+	if textCache.sha != sha || textCache.entries == nil {
+		textCache.sha, textCache.entries = sha, map[string]cachedText{}
+	}
+	t, ok := textCache.entries[key]
+	textCache.mu.Unlock()
+	if !ok {
+		body, err := build()
+		if errors.Is(err, os.ErrNotExist) {
+			NotFoundPage(c)
+			return
+		}
+		if err != nil {
+			log.Printf("Error building %s: %v", key, err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		sum := sha256.Sum256([]byte(body))
+		t = cachedText{body: body, etag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+		textCache.mu.Lock()
+		if textCache.sha == sha {
+			textCache.entries[key] = t
+		}
+		textCache.mu.Unlock()
+	}
+
+	c.Header("ETag", t.etag)
 	c.Header("Cache-Control", "public, max-age=3600")
-	c.String(http.StatusOK, b.String())
+	if etagMatches(c.GetHeader("If-None-Match"), t.etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Header("Content-Type", contentType)
+	c.String(http.StatusOK, t.body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag (or *),
+// by weak comparison as RFC 9110 specifies for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == strings.TrimPrefix(etag, "W/") || candidate == "*" {
+			return true
+		}
+	}
+	return false
 }
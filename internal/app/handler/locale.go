@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/i18n"
+)
+
+// localeCookie remembers a language picked in the switcher, so an explicit
+// choice is not undone by Accept-Language negotiation.
+const localeCookie = "rt_lang"
+
+const localeCookieMaxAge = 365 * 24 * 60 * 60
+
+// Localize serves the routes it guards in l: templates rendered with the
+// request context read the locale from it (see i18n.FromContext).
+func Localize(l i18n.Locale) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), l))
+		c.Next()
+	}
+}
+
+// NegotiateLocale redirects visitors of an unprefixed (English) localized
+// page to their preferred language from Accept-Language. Prefixed pages are
+// never redirected.
+//
+// The answer depends on the header alone, so it carries Vary:
+// Accept-Language and a shared cache can keep it. ?hl=<code>, which the
+// language switcher links to, records the choice in the rt_lang cookie;
+// a response decided by that cookie is marked private instead.
+func NegotiateLocale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if c.Request.Method != http.MethodGet || !i18n.IsLocalized(path) {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		if l, ok := i18n.Lookup(c.Query("hl")); ok {
+			setLocaleCookie(c, l)
+			c.Header("Cache-Control", "private, no-cache")
+			if l != i18n.Default {
+				c.Redirect(http.StatusFound, l.Path(path))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		want := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		if v, err := c.Cookie(localeCookie); err == nil {
+			if l, ok := i18n.Lookup(v); ok && l != want {
+				want = l
+				c.Header("Cache-Control", "private, no-cache")
+			}
+		}
+		if want != i18n.Default {
+			if c.Writer.Header().Get("Cache-Control") == "" {
+				c.Header("Cache-Control", htmlCacheControl)
+			}
+			c.Redirect(http.StatusFound, want.Path(path))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func setLocaleCookie(c *gin.Context, l i18n.Locale) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(localeCookie, l.Code, localeCookieMaxAge, "/", "", c.Request.TLS != nil, true)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/i18n"
+)
+
+// untranslatable is page text that reads the same in some locale:
+// product names, the postal address, and words German shares.
+var untranslatable = map[string]bool{
+	"maestro-runner ↗": true,
+	"DeviceLab.dev ↗":  true,
+	"Izinga Software Private Limited · IIIT Hyderabad, Gachibowli, Hyderabad 500032, India ·": true,
+	"01 · Standard": true,
+}
+
+var textNode = regexp.MustCompile(`>([^<]+)<`)
+
+// renderMain renders the page at path in l and returns its <main> text
+// nodes.
+func renderMain(t *testing.T, handler gin.HandlerFunc, path string, l i18n.Locale) []string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, l.Path(path), nil)
+	c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), l))
+	handler(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s in %s: status %d", path, l.Code, w.Code)
+	}
+	body := w.Body.String()
+	start, end := strings.Index(body, "<main"), strings.Index(body, "</main>")
+	if start < 0 || end < start {
+		t.Fatalf("%s in %s: no <main>", path, l.Code)
+	}
+	var texts []string
+	for _, m := range textNode.FindAllStringSubmatch(body[start:end+1], -1) {
+		if s := strings.TrimSpace(m[1]); strings.Contains(s, " ") {
+			texts = append(texts, s)
+		}
+	}
+	return texts
+}
+
+// TestLocalizedPagesAreTranslated fails when a page published under
+// /ja/... and /de/... still shows English prose: either its text is
+// missing from the catalogs, or the page should not be Localized.
+func TestLocalizedPagesAreTranslated(t *testing.T) {
+	for _, p := range sitePages {
+		if !p.Localized {
+			continue
+		}
+		english := renderMain(t, p.Handler, p.Path, i18n.Default)
+		for _, l := range i18n.Locales {
+			if l == i18n.Default {
+				continue
+			}
+			translated := make(map[string]bool)
+			for _, s := range renderMain(t, p.Handler, p.Path, l) {
+				translated[s] = true
+			}
+			for _, s := range english {
+				if translated[s] && !untranslatable[s] {
+					t.Errorf("%s: %q is not translated", l.Path(p.Path), s)
+				}
+			}
+		}
+	}
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	r := gin.New()
+	r.Use(NegotiateLocale(), ConditionalGET("v1"))
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "page") }
+	r.GET("/pricing", ok)
+	r.GET("/ja/pricing", ok)
+	r.GET("/contact", ok)
+
+	for _, tc := range []struct {
+		name, path, acceptLanguage, cookie string
+		wantStatus                         int
+		wantLocation                       string
+		wantVary, wantPrivate              bool
+	}{
+		{"English visitor", "/pricing", "en-GB,en;q=0.9", "", http.StatusOK, "", true, false},
+		{"Japanese visitor", "/pricing", "ja,en;q=0.5", "", http.StatusFound, "/ja/pricing", true, false},
+		{"prefixed page stays", "/ja/pricing", "de", "", http.StatusOK, "", false, false},
+		{"English-only page stays", "/contact", "ja", "", http.StatusOK, "", false, false},
+		{"switch to English", "/pricing?hl=en", "ja", "", http.StatusOK, "", true, true},
+		{"switch to German", "/pricing?hl=de", "en", "", http.StatusFound, "/de/pricing", true, true},
+		{"remembered English", "/pricing", "ja", "en", http.StatusOK, "", true, true},
+		{"remembered Japanese", "/pricing", "en", "ja", http.StatusFound, "/ja/pricing", true, true},
+		{"remembered choice the header agrees with", "/pricing", "ja", "ja", http.StatusFound, "/ja/pricing", true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: localeCookie, Value: tc.cookie})
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.wantStatus || w.Header().Get("Location") != tc.wantLocation {
+				t.Fatalf("got %d to %q, want %d to %q", w.Code, w.Header().Get("Location"), tc.wantStatus, tc.wantLocation)
+			}
+			if vary := strings.Contains(w.Header().Get("Vary"), "Accept-Language"); vary != tc.wantVary {
+				t.Errorf("Vary = %q", w.Header().Get("Vary"))
+			}
+			if private := strings.HasPrefix(w.Header().Get("Cache-Control"), "private"); private != tc.wantPrivate {
+				t.Errorf("Cache-Control = %q", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
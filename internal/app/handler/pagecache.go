@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/compress"
+	"github.com/izinga/robustest-web/internal/app/i18n"
+	"github.com/izinga/robustest-web/internal/app/site"
+)
+
+// renderedPages holds every registered page that renders the same for
+// everyone, by URL path (filled by InitPageCache).
+var renderedPages struct {
+	sync.RWMutex
+	byPath map[string]renderedPage
+}
+
+// renderedPage is a pre-rendered page in each encoding. With CSP on, the
+// bodies hold cspNoncePlaceholder and the gzip encoding is kept as a
+// compress.Spliced instead, for the request's nonce to be filled in.
+type renderedPage struct {
+	compress.Variants
+	nonced *compress.Spliced
+}
+
+// InitPageCache renders the registered pages in every locale once and
+// keeps them for Cached to serve. Dynamic pages and the docs home (which
+// follows the docs tree) are rendered per request. The pages are also
+// kept gzip- and brotli-encoded; with CSP on, where each response carries
+// its own nonce, only gzip can be spliced around it (see compress.Spliced),
+// and it is served to brotli clients too: on our pages that is about 10%
+// larger than brotli level 5 on the fly, which costs some 3 ms of CPU per
+// response. Encoding at the best levels takes a few seconds, so it happens
+// in the background; until it is done the pages are rendered as they are
+// asked for. Call it after the other Init functions, InitCSP included.
+func InitPageCache() {
+	go fillPageCache()
+}
+
+func fillPageCache() {
+	start := time.Now()
+	byPath := map[string]renderedPage{}
+	size := 0
+	for _, p := range site.Pages() {
+		if p.Dynamic || p.Path == "/docs" {
+			continue
+		}
+		locales := []i18n.Locale{i18n.Default}
+		if p.Localized {
+			locales = i18n.Locales
+		}
+		for _, l := range locales {
+			body, err := renderRegistered(p, l)
+			if err != nil {
+				log.Printf("Warning: page cache: %s: %v", l.Path(p.Path), err)
+				continue
+			}
+			var page renderedPage
+			if cspMode == cspOff {
+				page.Variants = compress.Precompress(bytes.ReplaceAll(body, []byte(cspNoncePlaceholder), nil))
+			} else {
+				page.Variants = compress.Variants{Identity: body}
+				page.nonced = compress.Splice(body, []byte(cspNoncePlaceholder), cspNonceLen)
+			}
+			byPath[l.Path(p.Path)] = page
+			size += len(page.Identity) + len(page.Gzip) + len(page.Brotli)
+		}
+	}
+	renderedPages.Lock()
+	renderedPages.byPath = byPath
+	renderedPages.Unlock()
+	log.Printf("Page cache: %d pages pre-rendered (%d KiB) in %s", len(byPath), size/1024, time.Since(start).Round(time.Millisecond))
+}
+
+// Cached serves the request's page from the pre-rendered cache in the
+// encoding the client prefers, falling back to h for pages not in it.
+func Cached(h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		renderedPages.RLock()
+		page, ok := renderedPages.byPath[c.Request.URL.Path]
+		renderedPages.RUnlock()
+		if !ok {
+			h(c)
+			return
+		}
+		body, coding := page.Pick(c.GetHeader("Accept-Encoding"))
+		if nonce := templ.GetNonce(c.Request.Context()); nonce != "" {
+			gz, ok := []byte(nil), false
+			if page.nonced != nil && compress.Accepts(c.GetHeader("Accept-Encoding"), compress.Gzip) {
+				gz, ok = page.nonced.Fill([]byte(nonce))
+			}
+			if ok {
+				body, coding = gz, compress.Gzip
+			} else {
+				// Encoded on the way out, if at all, by Compress.
+				body = bytes.ReplaceAll(page.Identity, []byte(cspNoncePlaceholder), []byte(nonce))
+				c.Data(http.StatusOK, "text/html; charset=utf-8", body)
+				return
+			}
+		}
+		header := c.Writer.Header()
+		compress.AddVary(header)
+		if coding != "" {
+			header.Set("Content-Encoding", coding)
+			if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				header.Set("ETag", "W/"+etag) // as compress.Middleware does
+			}
+		}
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Data(http.StatusOK, "text/html; charset=utf-8", body)
+	}
+}
+
+// Compress encodes the remaining responses on the fly once their body
+// reaches COMPRESS_MIN_BYTES (default 1024).
+func Compress() gin.HandlerFunc {
+	return compress.Middleware(envInt("COMPRESS_MIN_BYTES", 1024))
+}
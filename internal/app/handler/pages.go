@@ -123,7 +123,7 @@ func ContactPage(c *gin.Context) {
 		leadType = "partner"
 	}
 	renderPage(c, "contact", func() error {
-		return pages.ContactPage(leadType).Render(c.Request.Context(), c.Writer)
+		return pages.ContactPage(leadType, sourcePageFromReferer(c)).Render(c.Request.Context(), c.Writer)
 	})
 }
 
@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/booking"
+	"github.com/izinga/robustest-web/internal/app/feedback"
+	"github.com/izinga/robustest-web/internal/app/leads"
+	"github.com/izinga/robustest-web/internal/app/tokens"
+	"github.com/izinga/robustest-web/internal/app/views/pages"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// Data-subject requests (/privacy/request) are keyed by email address: the
+// request form only sends a signed link to that address, and the export or
+// deletion happens when its owner opens the link.
+const (
+	purposePrivacyExport = "privacy-export"
+	purposePrivacyDelete = "privacy-delete"
+	privacyLinkTTL       = 24 * time.Hour
+)
+
+// privacyRateLimiter throttles /privacy/request, which sends email.
+var privacyRateLimiter = &rateLimiter{
+	requests: make(map[string][]time.Time),
+	limit:    3,
+	window:   15 * time.Minute,
+}
+
+func renderPrivacyPage(c *gin.Context, status int, v pages.PrivacyView) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	c.Status(status)
+	renderPage(c, "privacy request", func() error {
+		return pages.PrivacyRequestPage(v).Render(c.Request.Context(), c.Writer)
+	})
+}
+
+// PrivacyRequestPage shows the export/delete request form.
+func PrivacyRequestPage(c *gin.Context) {
+	renderPrivacyPage(c, http.StatusOK, pages.PrivacyView{State: "form", Kind: "export"})
+}
+
+// SubmitPrivacyRequest emails a signed export or deletion link to the given
+// address if we hold anything for it. The answer is the same either way,
+// so the form cannot be used to learn whether someone contacted us.
+func SubmitPrivacyRequest(c *gin.Context) {
+	email := strings.ToLower(strings.TrimSpace(c.PostForm("email")))
+	kind := c.PostForm("kind")
+	form := pages.PrivacyView{State: "form", Email: email, Kind: kind}
+
+	if c.PostForm("website") != "" {
+		log.Printf("Honeypot triggered on privacy request from IP: %s", c.ClientIP())
+		renderPrivacyPage(c, http.StatusOK, pages.PrivacyView{State: "sent"})
+		return
+	}
+	if !privacyRateLimiter.isAllowed(c.ClientIP()) {
+		c.Header("Retry-After", fmt.Sprint(int(privacyRateLimiter.window.Seconds())))
+		form.Error = "Too many requests. Please wait a few minutes before trying again."
+		renderPrivacyPage(c, http.StatusTooManyRequests, form)
+		return
+	}
+	ok, err := verifyTurnstile(c.PostForm("cf-turnstile-response"), c.ClientIP())
+	if err != nil || !ok {
+		if err != nil {
+			log.Printf("Turnstile verification error: %v", err)
+		}
+		form.Error = "Verification failed. Please try again."
+		renderPrivacyPage(c, http.StatusForbidden, form)
+		return
+	}
+	if !emailRegex.MatchString(email) || len(email) > 254 {
+		form.Error = "Enter a valid email address."
+		renderPrivacyPage(c, http.StatusBadRequest, form)
+		return
+	}
+	if kind != "export" && kind != "delete" {
+		form.Error = "Choose whether you want a copy or a deletion."
+		renderPrivacyPage(c, http.StatusBadRequest, form)
+		return
+	}
+
+	held, err := holdsDataFor(email)
+	if err != nil {
+		log.Printf("Error looking up data for privacy request: %v", err)
+		form.Error = "Something went wrong. Please try again or email hello@robustest.com."
+		renderPrivacyPage(c, http.StatusInternalServerError, form)
+		return
+	}
+	if held {
+		if err := sendPrivacyLink(email, kind); err != nil {
+			log.Printf("Failed to send privacy %s link: %v", kind, err)
+		}
+	}
+	renderPrivacyPage(c, http.StatusOK, pages.PrivacyView{State: "sent", Email: email})
+}
+
+// holdsDataFor reports whether any submission or booking uses email, or
+// any docs feedback comment mentions it.
+func holdsDataFor(email string) (bool, error) {
+	found, err := leadStore.ByEmail(email)
+	if err != nil || len(found) > 0 {
+		return len(found) > 0, err
+	}
+	if demoScheduler != nil {
+		bookings, err := demoScheduler.ForEmail(email)
+		if err != nil || len(bookings) > 0 {
+			return len(bookings) > 0, err
+		}
+	}
+	if docsFeedback == nil {
+		return false, nil
+	}
+	comments, err := docsFeedback.ByEmail(email)
+	return len(comments) > 0, err
+}
+
+func sendPrivacyLink(email, kind string) error {
+	purpose, path, action := purposePrivacyExport, "/privacy/export", "download a copy of"
+	if kind == "delete" {
+		purpose, path, action = purposePrivacyDelete, "/privacy/delete", "delete"
+	}
+	link := siteURL(path + "?t=" + url.QueryEscape(linkSigner.Sign(purpose, email, time.Now().Add(privacyLinkTTL))))
+	htmlContent, textContent := buildNoticeEmail("", []string{
+		"Someone asked to " + action + " the information RobusTest holds for this email address: what was sent through the contact form on robustest.com, any demo bookings, and comments on our docs that mention the address.",
+		"If that was you, use the link below within 24 hours. If not, ignore this email; nothing will change.",
+	}, [][2]string{{"Open your request", link}})
+	return sendNotice(mail.NewEmail("", email), "Your RobusTest data request", htmlContent, textContent)
+}
+
+// privacyEmailFromToken resolves the address a privacy link was issued for,
+// rendering the error page and returning false when the link is bad.
+func privacyEmailFromToken(c *gin.Context, purpose, token string) (string, bool) {
+	email, err := linkSigner.Verify(purpose, token)
+	switch {
+	case errors.Is(err, tokens.ErrExpired):
+		renderPrivacyPage(c, http.StatusGone, pages.PrivacyView{State: "expired"})
+		return "", false
+	case err != nil:
+		renderPrivacyPage(c, http.StatusBadRequest, pages.PrivacyView{State: "invalid"})
+		return "", false
+	}
+	return email, true
+}
+
+// privacyExport is the download served by PrivacyExport.
+type privacyExport struct {
+	Email        string              `json:"email"`
+	ExportedAt   time.Time           `json:"exported_at"`
+	Submissions  []leads.Lead        `json:"submissions"`
+	Bookings     []booking.Booking   `json:"bookings"`
+	DocsComments []feedback.Feedback `json:"docs_comments"`
+}
+
+// PrivacyExport downloads everything stored for the link's address as JSON.
+func PrivacyExport(c *gin.Context) {
+	email, ok := privacyEmailFromToken(c, purposePrivacyExport, c.Query("t"))
+	if !ok {
+		return
+	}
+	export := privacyExport{Email: email, ExportedAt: time.Now().UTC()}
+	var err error
+	export.Submissions, err = leadStore.ByEmail(email)
+	if err == nil && demoScheduler != nil {
+		export.Bookings, err = demoScheduler.ForEmail(email)
+	}
+	if err == nil && docsFeedback != nil {
+		export.DocsComments, err = docsFeedback.ByEmail(email)
+	}
+	if err != nil {
+		log.Printf("Error exporting data for privacy request: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Privacy export served: %d submissions, %d bookings, %d docs comments", len(export.Submissions), len(export.Bookings), len(export.DocsComments))
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Disposition", `attachment; filename="robustest-data.json"`)
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+// PrivacyDeletePage asks the owner of the link's address to confirm deletion.
+func PrivacyDeletePage(c *gin.Context) {
+	token := c.Query("t")
+	if email, ok := privacyEmailFromToken(c, purposePrivacyDelete, token); ok {
+		renderPrivacyPage(c, http.StatusOK, pages.PrivacyView{State: "confirm-delete", Email: email, Token: token})
+	}
+}
+
+// PrivacyDelete erases every submission, contact-log entry and booking for
+// the link's address and every docs feedback comment mentioning it,
+// withdraws upcoming demo invites, and tells the team so copies in the
+// CRMs, mailboxes and Slack are removed too.
+func PrivacyDelete(c *gin.Context) {
+	email, ok := privacyEmailFromToken(c, purposePrivacyDelete, c.PostForm("t"))
+	if !ok {
+		return
+	}
+	submissions, err := leadStore.DeleteByEmail(email)
+	if err != nil {
+		log.Printf("Error deleting leads for privacy request: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	logged := 0
+	if contactLog != nil {
+		needle := strings.ToLower("| Email: " + email + " |")
+		logged, err = contactLog.Scrub(func(line string) bool {
+			return strings.Contains(strings.ToLower(line), needle)
+		})
+		if err != nil {
+			log.Printf("Error scrubbing the contact log for privacy request: %v", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+	var withdrawn []booking.Booking
+	if demoScheduler != nil {
+		withdrawn, err = demoScheduler.Forget(email)
+		if err != nil {
+			log.Printf("Error deleting bookings for privacy request: %v", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		for _, b := range withdrawn {
+			if err := sendBookingInvites(b, "Your RobusTest demo is canceled", ""); err != nil {
+				log.Printf("Failed to send cancellation for booking %s: %v", b.ID, err)
+			}
+		}
+	}
+	comments := 0
+	if docsFeedback != nil {
+		comments, err = docsFeedback.DeleteByEmail(email)
+		if err != nil {
+			log.Printf("Error deleting docs feedback for privacy request: %v", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+	log.Printf("Privacy deletion: removed %d submissions, %d contact-log entries and %d docs comments, withdrew %d bookings", submissions, logged, comments, len(withdrawn))
+
+	htmlContent, textContent := buildNoticeEmail("", []string{
+		fmt.Sprintf("%s asked for their data to be deleted and confirmed it from their inbox.", email),
+		fmt.Sprintf("The site removed %d contact submissions, %d contact-log entries, %d docs feedback comments mentioning the address and their demo bookings (%d upcoming invites withdrawn).", submissions, logged, comments, len(withdrawn)),
+		"Please delete them from HubSpot, Salesforce, the team mailboxes and the docs feedback Slack channel as well.",
+	}, nil)
+	if err := sendNotice(mail.NewEmail("RobusTest Team", inboxFor("privacy")), "Data deletion request", htmlContent, textContent); err != nil {
+		log.Printf("Failed to notify team of deletion request: %v", err)
+	}
+	renderPrivacyPage(c, http.StatusOK, pages.PrivacyView{State: "deleted"})
+}
+
+// VerifyEmailPage is where the confirmation link lands. Opening it only
+// shows a button, so mail scanners that prefetch links don't confirm
+// addresses on the visitor's behalf.
+func VerifyEmailPage(c *gin.Context) {
+	token := c.Query("t")
+	if _, ok := verifiedLeadID(c, token); ok {
+		renderVerifyPage(c, http.StatusOK, "confirm", token)
+	}
+}
+
+// VerifyEmail marks the lead behind the confirmation link verified.
+func VerifyEmail(c *gin.Context) {
+	id, ok := verifiedLeadID(c, c.PostForm("t"))
+	if !ok {
+		return
+	}
+	_, err := leadStore.MarkVerified(id, time.Now())
+	if errors.Is(err, leads.ErrNotFound) {
+		// Deleted on request since the email went out.
+		renderVerifyPage(c, http.StatusGone, "invalid", "")
+		return
+	}
+	if err != nil {
+		log.Printf("Error verifying lead %s: %v", id, err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Lead %s verified", id)
+	renderVerifyPage(c, http.StatusOK, "verified", "")
+}
+
+func verifiedLeadID(c *gin.Context, token string) (string, bool) {
+	id, err := linkSigner.Verify(purposeLeadVerify, token)
+	switch {
+	case errors.Is(err, tokens.ErrExpired):
+		renderVerifyPage(c, http.StatusGone, "expired", "")
+		return "", false
+	case err != nil:
+		renderVerifyPage(c, http.StatusBadRequest, "invalid", "")
+		return "", false
+	}
+	return id, true
+}
+
+func renderVerifyPage(c *gin.Context, status int, state, token string) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	c.Status(status)
+	renderPage(c, "verify email", func() error {
+		return pages.VerifyEmailPage(state, token).Render(c.Request.Context(), c.Writer)
+	})
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/izinga/robustest-web/internal/app/logfile"
+	"github.com/izinga/robustest-web/internal/app/redact"
+	"github.com/izinga/robustest-web/internal/app/retention"
+)
+
+// Retention defaults: leads are anonymized after a year, contact-log
+// segments deleted after 90 days, built-in analytics hits after 400 days
+// (a year and a month, for year-over-year reports), docs searches against
+// superseded docs 180 days after their last search, docs feedback after a
+// year, and the janitor checks once a day.
+const (
+	defaultLeadRetentionDays = 365
+	defaultLogRetentionDays  = 90
+	defaultAnalyticsDays     = 400
+	defaultDocsSearchDays    = 180
+	defaultDocsFeedbackDays  = 365
+	defaultRetentionInterval = 24 * time.Hour
+	defaultContactLogMaxMB   = 10
+	defaultContactLogRotate  = 24 * time.Hour
+)
+
+// contactLog is the rotating file behind contactFormLogger (nil when the
+// log goes to stdout).
+var contactLog *logfile.File
+
+// InitRetention applies the data retention policy. Call it after InitLeads,
+// InitBooking, InitDocs and InitAnalytics. It configures:
+//
+//	LOG_REDACTION                  mask (default), hash or none for emails
+//	                               and names in the server log
+//	CONTACT_LOG_FILE               contact-form log (default contact_form.log)
+//	CONTACT_LOG_MAX_MB             rotate at this size (default 10)
+//	CONTACT_LOG_ROTATE             rotate at this age (default 24h)
+//	LEAD_RETENTION_DAYS            age at which leads and past bookings are
+//	                               purged (default 365, 0 keeps them)
+//	LEAD_RETENTION_MODE            anonymize (default) or delete
+//	LOG_RETENTION_DAYS             age at which contact-log segments are
+//	                               deleted (default 90, 0 keeps them)
+//	ANALYTICS_RETENTION_DAYS       age at which built-in analytics hits are
+//	                               deleted (default 400, 0 keeps them)
+//	DOCS_SEARCH_RETENTION_DAYS     age of the last search against a
+//	                               superseded docs tree at which its
+//	                               searches are deleted (default 180, 0
+//	                               keeps them)
+//	DOCS_FEEDBACK_RETENTION_DAYS   age at which docs votes and their
+//	                               comments are deleted (default 365, 0
+//	                               keeps them)
+//	RETENTION_INTERVAL             how often the janitor runs (default 24h)
+func InitRetention() {
+	log.Printf("Log redaction: %s", redact.SetMode(os.Getenv("LOG_REDACTION")))
+
+	path := os.Getenv("CONTACT_LOG_FILE")
+	if path == "" {
+		path = "contact_form.log"
+	}
+	maxMB := envInt("CONTACT_LOG_MAX_MB", defaultContactLogMaxMB)
+	f, err := logfile.Open(path, int64(maxMB)<<20, envDuration("CONTACT_LOG_ROTATE", defaultContactLogRotate))
+	if err != nil {
+		log.Printf("Warning: Could not open %s: %v", path, err)
+	} else {
+		contactLog = f
+		contactFormLogger = log.New(f, "", log.LstdFlags)
+	}
+
+	anonymize := os.Getenv("LEAD_RETENTION_MODE") != "delete"
+	leadKeep := retention.Days(envInt("LEAD_RETENTION_DAYS", defaultLeadRetentionDays))
+	jobs := []retention.Job{{
+		Name: "leads",
+		Keep: leadKeep,
+		Purge: func(cutoff time.Time) (int, error) {
+			return leadStore.Purge(cutoff, anonymize)
+		},
+	}}
+	if demoScheduler != nil {
+		jobs = append(jobs, retention.Job{
+			Name: "bookings",
+			Keep: leadKeep,
+			Purge: func(cutoff time.Time) (int, error) {
+				return demoScheduler.Purge(cutoff, anonymize)
+			},
+		})
+	}
+	if contactLog != nil {
+		jobs = append(jobs, retention.Job{
+			Name:  "contact log",
+			Keep:  retention.Days(envInt("LOG_RETENTION_DAYS", defaultLogRetentionDays)),
+			Purge: contactLog.Purge,
+		})
+	}
+	if analyticsStore != nil {
+		jobs = append(jobs, retention.Job{
+			Name:  "analytics",
+			Keep:  retention.Days(envInt("ANALYTICS_RETENTION_DAYS", defaultAnalyticsDays)),
+			Purge: analyticsStore.Purge,
+		})
+	}
+	if docsSearches != nil {
+		jobs = append(jobs, retention.Job{
+			Name: "docs searches",
+			Keep: retention.Days(envInt("DOCS_SEARCH_RETENTION_DAYS", defaultDocsSearchDays)),
+			Purge: func(cutoff time.Time) (int, error) {
+				if !docsStore.Ready() {
+					return 0, nil // the published SHA isn't known yet
+				}
+				current, _ := docsStore.Published()
+				return docsSearches.Purge(cutoff, current)
+			},
+		})
+	}
+	if docsFeedback != nil {
+		jobs = append(jobs, retention.Job{
+			Name:  "docs feedback",
+			Keep:  retention.Days(envInt("DOCS_FEEDBACK_RETENTION_DAYS", defaultDocsFeedbackDays)),
+			Purge: docsFeedback.Purge,
+		})
+	}
+	retention.NewJanitor(envDuration("RETENTION_INTERVAL", defaultRetentionInterval), jobs...).Start()
+}
+
+// envInt reads a non-negative integer setting, falling back to def when
+// unset or invalid.
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Printf("Warning: invalid %s %q, using %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// envDuration reads a duration setting of at least a minute, falling back
+// to def when unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < time.Minute {
+		log.Printf("Warning: invalid %s %q, using %s", key, v, def)
+		return def
+	}
+	return d
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+// securityTxt is /.well-known/security.txt (RFC 9116), built by
+// InitSecurityTxt.
+var securityTxt []byte
+
+// InitSecurityTxt builds security.txt from SECURITY_CONTACT (comma-separated
+// email addresses or URLs, default hello@robustest.com), SECURITY_POLICY
+// (default the /security page), SECURITY_ENCRYPTION (URL of a public key,
+// optional) and SECURITY_TXT_EXPIRES (how long after start it is valid,
+// default 4320h; the RFC advises less than a year). When
+// SECURITY_TXT_SIGNING_KEY names an ASCII-armored OpenPGP private key
+// (unlocked with SECURITY_TXT_SIGNING_PASSPHRASE if needed), the file is
+// served clearsigned.
+func InitSecurityTxt() {
+	contacts := os.Getenv("SECURITY_CONTACT")
+	if contacts == "" {
+		contacts = "hello@robustest.com"
+	}
+	policy := os.Getenv("SECURITY_POLICY")
+	if policy == "" {
+		policy = siteURL("/security")
+	}
+	expires := time.Now().Add(envDuration("SECURITY_TXT_EXPIRES", 180*24*time.Hour))
+
+	var b strings.Builder
+	for _, c := range strings.Split(contacts, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if !strings.Contains(c, ":") {
+			c = "mailto:" + c
+		}
+		fmt.Fprintf(&b, "Contact: %s\n", c)
+	}
+	fmt.Fprintf(&b, "Expires: %s\n", expires.UTC().Format(time.RFC3339))
+	if enc := os.Getenv("SECURITY_ENCRYPTION"); enc != "" {
+		fmt.Fprintf(&b, "Encryption: %s\n", enc)
+	}
+	fmt.Fprintf(&b, "Policy: %s\n", policy)
+	b.WriteString("Preferred-Languages: en\n")
+	fmt.Fprintf(&b, "Canonical: %s\n", siteURL("/.well-known/security.txt"))
+	securityTxt = []byte(b.String())
+
+	keyFile := os.Getenv("SECURITY_TXT_SIGNING_KEY")
+	if keyFile == "" {
+		return
+	}
+	signed, err := clearsignWith(keyFile, os.Getenv("SECURITY_TXT_SIGNING_PASSPHRASE"), securityTxt)
+	if err != nil {
+		log.Printf("Warning: security.txt served unsigned: %v", err)
+		return
+	}
+	securityTxt = signed
+}
+
+// clearsignWith signs body with the first private key in the armored key
+// file.
+func clearsignWith(keyFile, passphrase string, body []byte) ([]byte, error) {
+	f, err := os.Open(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	keys, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", keyFile, err)
+	}
+	for _, e := range keys {
+		key := e.PrivateKey
+		if key == nil {
+			continue
+		}
+		if key.Encrypted {
+			if err := key.Decrypt([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("unlocking %s: %w", keyFile, err)
+			}
+		}
+		var out bytes.Buffer
+		w, err := clearsign.Encode(&out, key, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+	return nil, fmt.Errorf("no private key in %s", keyFile)
+}
+
+// SecurityTxt serves /.well-known/security.txt.
+func SecurityTxt(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", securityTxt)
+}
+
+// LegacySecurityTxt sends /security.txt to the RFC 9116 location.
+func LegacySecurityTxt(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, "/.well-known/security.txt")
+}
@@ -0,0 +1,262 @@
+package handler
+
+import "github.com/izinga/robustest-web/internal/app/site"
+
+// sitePages is the site's page registry: routes, sitemap, llms.txt,
+// breadcrumbs and the header and footer menus are all generated from it.
+// Order is navigation order. Add a page here, not in main.go.
+var sitePages = []site.Page{
+	{
+		Path:        "/",
+		Handler:     HomePage,
+		Title:       "Enterprise On-Premise Device Lab | RobusTest",
+		Description: "The enterprise device lab on your premises: real phones, tablets, and TVs inside your network, with automation, live manual testing, performance vitals, and network capture — no per-minute billing, nothing leaving your walls.",
+		Name:        "Home",
+		Summary:     "What RobusTest is: the enterprise device lab on your premises",
+		Priority:    1.0,
+		ChangeFreq:  "weekly",
+		Images:      []string{"live-session", "perf-compare"},
+	},
+	{
+		Path:        "/features",
+		Handler:     FeaturesPage,
+		Title:       "Platform overview — RobusTest on-premise device lab",
+		Description: "One on-premise lab for manual testing, automation, performance vitals, Smart TV testing, network capture, lab operations, and enterprise integrations — on your own devices, inside your network.",
+		Name:        "Platform overview",
+		Summary:     "All seven capabilities and how they fit together",
+		Priority:    0.9,
+		ChangeFreq:  "monthly",
+		Nav: []site.NavLink{
+			{Menu: site.Platform, Label: "Platform overview", Desc: "Everything in one on-premise lab"},
+		},
+	},
+	{
+		Path:        "/platform/manual-testing",
+		Handler:     ManualTestingPage,
+		Title:       "Live manual testing on real devices — RobusTest",
+		Description: "Pick a real phone, tablet, or TV from your lab and drive it from the browser at 10–20 ms latency — touch, type, GPS, shell, and logs, with performance vitals and network capture recorded on every session by default.",
+		Name:        "Manual testing",
+		Summary:     "Live device control from the browser: touch, GPS, shell, logs, vitals",
+		Parent:      "/features",
+		Priority:    0.9,
+		ChangeFreq:  "monthly",
+		Images:      []string{"live-session", "optical"},
+		Nav: []site.NavLink{
+			{Menu: site.Platform, Label: "Manual testing", Tag: "LIVE", Desc: "Real devices in the browser, 10–20 ms away"},
+		},
+	},
+	{
+		Path:        "/platform/test-automation",
+		Handler:     TestAutomationPage,
+		Title:       "Mobile test automation on your own devices — RobusTest",
+		Description: "Run Appium, Espresso, XCUITest, Selenium, UIAutomator, and Maestro-style flows in parallel across your own device pool — triggered from CI, with JUnit output and nothing leaving your network.",
+		Name:        "Test automation",
+		Summary:     "Appium hub, Espresso, XCUITest, Selenium grid, parallel runs, CI API",
+		Parent:      "/features",
+		Priority:    0.9,
+		ChangeFreq:  "monthly",
+		Images:      []string{"run-report"},
+		Nav: []site.NavLink{
+			{Menu: site.Platform, Label: "Test automation", Tag: "AUTO", Desc: "Appium, Espresso, XCUITest, Selenium & flows"},
+		},
+	},
+	{
+		Path:        "/platform/performance-testing",
+		Handler:     PerformanceTestingPage,
+		Title:       "Performance testing with no SDK — RobusTest",
+		Description: "Every test session captures performance vitals by default — FPS, jank, CPU, memory, battery, and thermal on real phones, tablets, and TVs, with no SDK and no code changes — then compared build-over-build with real statistics.",
+		Name:        "Performance testing",
+		Summary:     "No-SDK vitals on every session; build-over-build regression statistics",
+		Parent:      "/features",
+		Priority:    0.9,
+		ChangeFreq:  "monthly",
+		Images:      []string{"perf-compare"},
+		Nav: []site.NavLink{
+			{Menu: site.Platform, Label: "Performance testing", Tag: "PERF", Desc: "No-SDK vitals: FPS, CPU, memory, thermal"},
+		},
+	},
+	{
+		Path:        "/platform/tv-testing",
+		Handler:     TVTestingPage,
+		Title:       "Smart TV & OTT testing on real panels — RobusTest",
+		Description: "Automate and manually test Samsung Tizen, LG webOS, Roku, Apple TV, Android TV, Fire TV — plus cable boxes, Xbox, and PlayStation — on real hardware in your own lab, with live video and audio over HDMI capture and TV performance vitals.",
+		Name:        "Smart TV & OTT testing",
+		Summary:     "Tizen, webOS, Roku, Apple TV, Android/Fire TV, consoles; model-year support matrix",
+		Parent:      "/features",
+		Priority:    0.9,
+		ChangeFreq:  "monthly",
+		Images:      []string{"tv-session"},
+		Nav: []site.NavLink{
+			{Menu: site.Platform, Label: "Smart TV & OTT", Tag: "TV", Desc: "Tizen, webOS, Roku, Apple TV, Android TV"},
+		},
+	},
+	{
+		Path:        "/platform/network-capture",
+		Handler:     NetworkCapturePage,
+		Title:       "Network capture and mocking for app testing — RobusTest",
+		Description: "Every HTTP(S) call your app makes, captured automatically as HAR — with live inspection, gRPC/protobuf decoding, rewrite rules, and breakpoints, on devices that already sit inside your network.",
+		Name:        "Network capture",
+		Summary:     "Automatic HAR capture, HTTPS inspection, gRPC/protobuf decoding, mocking",
+		Parent:      "/features",
+		Priority:    0.9,
+		ChangeFreq:  "monthly",
+		Images:      []string{"har-view"},
+		Nav: []site.NavLink{
+			{Menu: site.Platform, Label: "Network capture", Tag: "NET", Desc: "HAR capture, HTTPS inspection & mocking"},
+		},
+	},
+	{
+		Path:        "/platform/device-lab",
+		Handler:     DeviceLabPage,
+		Title:       "Device lab operations — RobusTest",
+		Description: "Run fifty phones and a TV wall as reliable infrastructure: health scoring, booking, smart power control, iOS MDM for unattended installs, a versioned build library, and multi-site lab nodes.",
+		Name:        "Device lab operations",
+		Summary:     "Health scoring, booking, smart power control, iOS MDM, build library",
+		Parent:      "/features",
+		Priority:    0.9,
+		ChangeFreq:  "monthly",
+		Images:      []string{"fleet"},
+		Nav: []site.NavLink{
+			{Menu: site.Platform, Label: "Device lab operations", Tag: "LAB", Desc: "Health, booking, power control, MDM"},
+		},
+	},
+	{
+		Path:        "/platform/integrations",
+		Handler:     IntegrationsPage,
+		Title:       "Integrations & enterprise — RobusTest",
+		Description: "Google and Microsoft SSO, JIRA, ReportPortal, Slack, InfluxDB, JUnit output, and a documented CI/CD API — RobusTest plugs into the stack your team already runs.",
+		Name:        "Integrations & enterprise",
+		Summary:     "Google/Microsoft SSO, JIRA, ReportPortal, Slack, InfluxDB, CI/CD API",
+		Parent:      "/features",
+		Priority:    0.9,
+		ChangeFreq:  "monthly",
+		Images:      []string{"integrations"},
+		Nav: []site.NavLink{
+			{Menu: site.Platform, Label: "Integrations & enterprise", Tag: "ENT", Desc: "SSO, JIRA, ReportPortal, Slack, CI/CD"},
+		},
+	},
+	{
+		Path:        "/enterprise",
+		Handler:     EnterprisePage,
+		Title:       "Enterprise on-premise device labs by industry — RobusTest",
+		Description: "How streaming platforms, banks, and telcos run RobusTest as their in-house device lab: TV walls with DRM-safe capture, air-gapped deployments, and multi-site labs — every device inside their own network.",
+		Name:        "For enterprise teams",
+		Summary:     "Industry scenarios: streaming/OTT, banking & fintech, telecom",
+		Priority:    0.8,
+		ChangeFreq:  "monthly",
+		Nav: []site.NavLink{
+			{Menu: site.Header, Label: "Enterprise"},
+			{Menu: site.Company, Label: "For enterprise"},
+		},
+	},
+	{
+		Path:        "/partners",
+		Handler:     PartnersPage,
+		Title:       "Partners — deliver on-premise device labs to your clients | RobusTest",
+		Description: "Testing services companies run RobusTest labs at their own sites and deliver testing their clients' compliance teams can approve. You own the engagement and the client; we stay the platform. We don't do services — ever.",
+		Name:        "For testing services companies",
+		Summary:     "Partner model: run RobusTest labs at your site, deliver testing to clients",
+		Priority:    0.8,
+		ChangeFreq:  "monthly",
+		Nav: []site.NavLink{
+			{Menu: site.Header, Label: "Partners"},
+			{Menu: site.Company, Label: "Partner with us"},
+		},
+	},
+	{
+		Path:        "/docs",
+		Handler:     DocsPage,
+		Title:       "Documentation — RobusTest Docs",
+		Description: "RobusTest product documentation: setting up the lab, testing on its devices, automation, and administration.",
+		Name:        "Documentation",
+		Summary:     "Product documentation: lab setup, manual and automated testing, administration",
+		Priority:    0.7,
+		ChangeFreq:  "weekly",
+		Nav: []site.NavLink{
+			{Menu: site.Header, Label: "Docs"},
+			{Menu: site.Company, Label: "Documentation"},
+		},
+	},
+	{
+		Path:        "/pricing",
+		Handler:     PricingPage,
+		Title:       "Pricing — flat yearly license, unlimited users — RobusTest",
+		Description: "RobusTest is licensed per device seat with a flat yearly price. Unlimited users, unlimited test minutes, all capabilities included. No per-minute billing, ever.",
+		Name:        "Pricing",
+		Summary:     "Flat yearly license by device count; unlimited users and minutes; hardware included",
+		Priority:    0.8,
+		ChangeFreq:  "monthly",
+		Localized:   true,
+		Nav: []site.NavLink{
+			{Menu: site.Header, Label: "Pricing"},
+			{Menu: site.Company, Label: "Pricing"},
+		},
+	},
+	{
+		Path:        "/security",
+		Handler:     SecurityPage,
+		Title:       "Security — on-premise by architecture — RobusTest",
+		Description: "RobusTest runs entirely inside your network: builds, test data, and device traffic never leave your premises. TLS, OAuth2 SSO, certificate-based device auth, and air-gapped deployment.",
+		Name:        "Security",
+		Summary:     "On-premise architecture, data ownership policy, air-gap, SSO, device certificates",
+		Priority:    0.7,
+		ChangeFreq:  "monthly",
+		Nav: []site.NavLink{
+			{Menu: site.Header, Label: "Security"},
+			{Menu: site.Company, Label: "Security"},
+		},
+	},
+	{
+		Path:        "/about",
+		Handler:     AboutPage,
+		Title:       "About — RobusTest by Izinga Software",
+		Description: "RobusTest is built by Izinga Software in Hyderabad, India — engineers who have run enterprise device labs since 2014 and build open-source testing tools in the open.",
+		Name:        "About",
+		Summary:     "Izinga Software, Hyderabad; building device labs since 2014",
+		Priority:    0.6,
+		ChangeFreq:  "yearly",
+		Localized:   true,
+		Nav: []site.NavLink{
+			{Menu: site.Header, Label: "About"},
+			{Menu: site.Company, Label: "About"},
+		},
+	},
+	{
+		Path:        "/contact",
+		Handler:     ContactPage,
+		Title:       "Contact — book a demo or get a quote — RobusTest",
+		Description: "Talk to the RobusTest team about an on-premise device lab for your organization: a live demo, a lab spec for your device list, or a license quote.",
+		Name:        "Contact",
+		Summary:     "Book a demo or request a lab quote",
+		Priority:    0.5,
+		ChangeFreq:  "yearly",
+		Dynamic:     true,
+		Nav: []site.NavLink{
+			{Menu: site.Company, Label: "Contact"},
+		},
+	},
+	{
+		Path:        "/legal",
+		Handler:     LegalPage,
+		Title:       "Privacy & Terms — RobusTest",
+		Description: "Privacy policy and terms of service for robustest.com and the RobusTest on-premise device lab platform.",
+		Name:        "Privacy & Terms",
+		Summary:     "Privacy policy and terms of service, including data retention",
+		Priority:    0.3,
+		ChangeFreq:  "yearly",
+		Nav: []site.NavLink{
+			{Menu: site.Legal, Label: "Privacy & Terms"},
+		},
+	},
+}
+
+func init() {
+	site.Register(sitePages...)
+	for _, p := range sitePages {
+		for _, slug := range p.Images {
+			if _, ok := illustrations[slug]; !ok {
+				panic("site: page " + p.Path + ": unknown illustration " + slug)
+			}
+		}
+	}
+}
@@ -1,72 +1,247 @@
 package handler
 
 import (
+	"bytes"
 	"fmt"
+	"log"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/a-h/templ"
 	"github.com/gin-gonic/gin"
+	"github.com/izinga/robustest-web/internal/app/i18n"
+	"github.com/izinga/robustest-web/internal/app/lastmod"
+	"github.com/izinga/robustest-web/internal/app/site"
 )
 
-// marketingPages are the static site URLs with their sitemap priority.
-var marketingPages = []struct {
-	Path     string
-	Priority string
-}{
-	{"/", "1.0"},
-	{"/features", "0.9"},
-	{"/platform/manual-testing", "0.9"},
-	{"/platform/test-automation", "0.9"},
-	{"/platform/performance-testing", "0.9"},
-	{"/platform/tv-testing", "0.9"},
-	{"/platform/network-capture", "0.9"},
-	{"/platform/device-lab", "0.9"},
-	{"/platform/integrations", "0.9"},
-	{"/enterprise", "0.8"},
-	{"/partners", "0.8"},
-	{"/pricing", "0.8"},
-	{"/security", "0.7"},
-	{"/docs", "0.7"},
-	{"/about", "0.6"},
-	{"/contact", "0.5"},
-	{"/legal", "0.3"},
+// pageDates holds when each page URL last changed (nil until InitSitemap).
+var pageDates *lastmod.Store
+
+// InitSitemap fingerprints every registered page in every locale and
+// records the ones whose content changed since the last start
+// (SITEMAP_STATE_FILE, default ./data/lastmod.json). Call it after the
+// other Init functions, as it renders the pages.
+func InitSitemap() {
+	store, err := lastmod.OpenFromEnv()
+	if err != nil {
+		log.Printf("Warning: sitemap dates unavailable: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, p := range site.Pages() {
+		if p.Path == "/docs" {
+			continue // dated by the docs repo's commits
+		}
+		locales := []i18n.Locale{i18n.Default}
+		if p.Localized {
+			locales = i18n.Locales
+		}
+		for _, l := range locales {
+			body, err := renderForFingerprint(p, l)
+			if err != nil {
+				log.Printf("Warning: sitemap: %s: %v", l.Path(p.Path), err)
+				continue
+			}
+			store.Observe(l.Path(p.Path), body, now)
+		}
+	}
+	if err := store.Save(); err != nil {
+		log.Printf("Warning: Could not save sitemap dates: %v", err)
+	}
+	pageDates = store
+}
+
+// renderForFingerprint renders p in l and returns the part of it whose
+// changes re-date the page: the title, the meta description and <main>.
+// Header and footer are shared chrome (the footer even carries the year),
+// and editing them shouldn't make every page look new.
+func renderForFingerprint(p site.Page, l i18n.Locale) ([]byte, error) {
+	html, err := renderRegistered(p, l)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	for _, part := range [][2]string{
+		{"<title>", "</title>"},
+		{`<meta name="description"`, ">"},
+		{"<main", "</main>"},
+	} {
+		out = append(out, between(html, part[0], part[1])...)
+	}
+	return out, nil
 }
 
-// SitemapXML emits the sitemap dynamically: static marketing pages plus
-// every page in the currently published docs tree.
+// renderRegistered renders the page p in l outside of any request, with
+// cspNoncePlaceholder for the nonce.
+func renderRegistered(p site.Page, l i18n.Locale) ([]byte, error) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodGet, l.Path(p.Path), nil)
+	ctx := templ.WithNonce(i18n.WithLocale(req.Context(), l), cspNoncePlaceholder)
+	c.Request = req.WithContext(ctx)
+	p.Handler(c)
+	if w.Code != http.StatusOK {
+		return nil, fmt.Errorf("rendered %d", w.Code)
+	}
+	return w.Body.Bytes(), nil
+}
+
+// between returns the first span of s from start through end.
+func between(s []byte, start, end string) []byte {
+	i := bytes.Index(s, []byte(start))
+	if i < 0 {
+		return nil
+	}
+	j := bytes.Index(s[i:], []byte(end))
+	if j < 0 {
+		return s[i:]
+	}
+	return s[i : i+j+len(end)]
+}
+
+// sitemapURL is one <url> of the sitemap.
+type sitemapURL struct {
+	Path       string
+	Lastmod    time.Time // omitted when zero
+	ChangeFreq string
+	Priority   float64
+	// Localizes names the unprefixed page a localized URL translates; its
+	// locale versions become the URL's alternates.
+	Localizes string
+	Images    []string // illustration slugs
+}
+
+// SitemapXML serves /sitemap.xml as a sitemap index over the page and
+// docs sitemaps, each dated by its most recent entry.
 func SitemapXML(c *gin.Context) {
 	var b strings.Builder
 	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
-	b.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
-
-	today := time.Now().UTC().Format("2006-01-02")
-	docsDate := today
-	if docsStore != nil {
-		if _, syncedAt, _ := docsStore.Status(); !syncedAt.IsZero() {
-			docsDate = syncedAt.UTC().Format("2006-01-02")
+	b.WriteString(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, sm := range []struct {
+		path string
+		urls []sitemapURL
+	}{
+		{"/sitemaps/pages.xml", pageURLs()},
+		{"/sitemaps/docs.xml", docURLs()},
+	} {
+		fmt.Fprintf(&b, "  <sitemap>\n    <loc>https://robustest.com%s</loc>\n", sm.path)
+		var latest time.Time
+		for _, u := range sm.urls {
+			if u.Lastmod.After(latest) {
+				latest = u.Lastmod
+			}
+		}
+		if !latest.IsZero() {
+			fmt.Fprintf(&b, "    <lastmod>%s</lastmod>\n", latest.UTC().Format("2006-01-02"))
 		}
+		b.WriteString("  </sitemap>\n")
 	}
+	b.WriteString("</sitemapindex>\n")
+	writeSitemap(c, b.String())
+}
+
+// SitemapPages serves /sitemaps/pages.xml: the registered pages, in every
+// locale they are translated into. Localized pages list their alternates
+// (xhtml:link hreflang) so search engines pair the translations, and pages
+// showing illustrations list them as images. lastmod is when the page's
+// content last changed (see InitSitemap).
+func SitemapPages(c *gin.Context) {
+	writeSitemap(c, urlset(pageURLs()))
+}
 
-	write := func(path, lastmod, priority string) {
-		fmt.Fprintf(&b, "  <url>\n    <loc>https://robustest.com%s</loc>\n    <lastmod>%s</lastmod>\n    <priority>%s</priority>\n  </url>\n",
-			path, lastmod, priority)
+// SitemapDocs serves /sitemaps/docs.xml: every page in the currently
+// published docs tree, dated by its file's last commit. The docs are not
+// versioned; a versioned tree would get one sitemap per version here.
+func SitemapDocs(c *gin.Context) {
+	writeSitemap(c, urlset(docURLs()))
+}
+
+func pageURLs() []sitemapURL {
+	var urls []sitemapURL
+	for _, p := range site.Pages() {
+		u := sitemapURL{Path: p.Path, ChangeFreq: p.ChangeFreq, Priority: p.Priority, Images: p.Images}
+		if p.Path == "/docs" {
+			if docsStore != nil && docsStore.Ready() {
+				u.Lastmod = docsStore.LastModified("")
+			}
+			urls = append(urls, u)
+			continue
+		}
+		if !p.Localized {
+			u.Lastmod = pageChanged(p.Path)
+			urls = append(urls, u)
+			continue
+		}
+		for _, l := range i18n.Locales {
+			u.Path, u.Localizes = l.Path(p.Path), p.Path
+			u.Lastmod = pageChanged(u.Path)
+			urls = append(urls, u)
+		}
 	}
+	return urls
+}
 
-	for _, p := range marketingPages {
-		write(p.Path, today, p.Priority)
+func docURLs() []sitemapURL {
+	if docsStore == nil || !docsStore.Ready() {
+		return nil
 	}
-	if docsStore != nil && docsStore.Ready() {
-		for _, entry := range docsStore.Index() {
-			if entry.Path == "" {
-				continue // /docs home already listed
-			}
-			write("/docs/"+entry.Path, docsDate, "0.6")
+	var urls []sitemapURL
+	for _, entry := range docsStore.Index() {
+		if entry.Path == "" {
+			continue // /docs home is a registered page
 		}
+		urls = append(urls, sitemapURL{
+			Path:       "/docs/" + entry.Path,
+			Lastmod:    docsStore.LastModified(entry.Path),
+			ChangeFreq: "monthly",
+			Priority:   0.6,
+		})
 	}
+	return urls
+}
 
+// urlset renders urls as a sitemap.
+func urlset(urls []sitemapURL) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:xhtml="http://www.w3.org/1999/xhtml" xmlns:image="http://www.google.com/schemas/sitemap-image/1.1">` + "\n")
+	for _, u := range urls {
+		fmt.Fprintf(&b, "  <url>\n    <loc>https://robustest.com%s</loc>\n", u.Path)
+		if !u.Lastmod.IsZero() {
+			fmt.Fprintf(&b, "    <lastmod>%s</lastmod>\n", u.Lastmod.UTC().Format("2006-01-02"))
+		}
+		fmt.Fprintf(&b, "    <changefreq>%s</changefreq>\n    <priority>%s</priority>\n",
+			u.ChangeFreq, strconv.FormatFloat(u.Priority, 'f', 1, 64))
+		if u.Localizes != "" {
+			for _, l := range i18n.Locales {
+				fmt.Fprintf(&b, "    <xhtml:link rel=\"alternate\" hreflang=\"%s\" href=\"https://robustest.com%s\"/>\n", l.Code, l.Path(u.Localizes))
+			}
+			fmt.Fprintf(&b, "    <xhtml:link rel=\"alternate\" hreflang=\"x-default\" href=\"https://robustest.com%s\"/>\n", u.Localizes)
+		}
+		for _, slug := range u.Images {
+			fmt.Fprintf(&b, "    <image:image>\n      <image:loc>%s</image:loc>\n    </image:image>\n", illustrationURL(slug))
+		}
+		b.WriteString("  </url>\n")
+	}
 	b.WriteString("</urlset>\n")
+	return b.String()
+}
+
+func writeSitemap(c *gin.Context, xml string) {
 	c.Header("Content-Type", "application/xml; charset=utf-8")
 	c.Header("Cache-Control", "public, max-age=3600")
-	c.String(http.StatusOK, b.String())
+	c.String(http.StatusOK, xml)
+}
+
+// pageChanged returns when the page at the URL path last changed, or the
+// zero time if it was never fingerprinted.
+func pageChanged(path string) time.Time {
+	if pageDates == nil {
+		return time.Time{}
+	}
+	e, _ := pageDates.Get(path)
+	return e.Changed
 }
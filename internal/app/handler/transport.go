@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hstsHeader is the Strict-Transport-Security value sent over HTTPS ("" when
+// HSTS is off). Set by InitTransportSecurity.
+var hstsHeader string
+
+// trustedProxies are the networks whose X-Forwarded-For and
+// X-Forwarded-Proto we believe (TRUSTED_PROXIES). Set by
+// InitTransportSecurity.
+var (
+	trustedProxySpecs []string
+	trustedProxies    []*net.IPNet
+)
+
+// hstsMinPreload is the shortest max-age the browsers' preload list accepts.
+const hstsMinPreload = 31536000
+
+// InitTransportSecurity reads the HSTS settings, HSTS_MAX_AGE in seconds
+// (default one year, 0 turns HSTS off), HSTS_INCLUDE_SUBDOMAINS and
+// HSTS_PRELOAD ("true"), and TRUSTED_PROXIES, a comma-separated list of
+// addresses or CIDR ranges of the reverse proxies in front of the server
+// (none by default: robustest.com terminates TLS itself).
+func InitTransportSecurity() {
+	trustedProxySpecs, trustedProxies = nil, nil
+	for _, s := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		n, err := parseProxy(s)
+		if err != nil {
+			log.Printf("Warning: TRUSTED_PROXIES: %v", err)
+			continue
+		}
+		trustedProxySpecs = append(trustedProxySpecs, s)
+		trustedProxies = append(trustedProxies, n)
+	}
+
+	maxAge := envInt("HSTS_MAX_AGE", hstsMinPreload)
+	if maxAge == 0 {
+		hstsHeader = ""
+		return
+	}
+	subdomains := os.Getenv("HSTS_INCLUDE_SUBDOMAINS") == "true"
+	preload := os.Getenv("HSTS_PRELOAD") == "true"
+	if preload && maxAge < hstsMinPreload {
+		log.Printf("Warning: HSTS_PRELOAD needs HSTS_MAX_AGE of at least %d; not sending preload", hstsMinPreload)
+		preload = false
+	}
+	hstsHeader = fmt.Sprintf("max-age=%d", maxAge)
+	if subdomains || preload { // the preload list requires includeSubDomains
+		hstsHeader += "; includeSubDomains"
+	}
+	if preload {
+		hstsHeader += "; preload"
+	}
+}
+
+func parseProxy(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, n, err := net.ParseCIDR(s)
+		return n, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid address %q", s)
+	}
+	bits := 8 * net.IPv4len
+	if ip.To4() == nil {
+		bits = 8 * net.IPv6len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// TrustedProxies returns TRUSTED_PROXIES as given, for
+// gin.Engine.SetTrustedProxies (so ClientIP reads X-Forwarded-For from them
+// alone). Call it after InitTransportSecurity.
+func TrustedProxies() []string {
+	return trustedProxySpecs
+}
+
+// StrictTransportSecurity sends Strict-Transport-Security on responses to
+// requests that arrived over HTTPS: on a TLS connection, or through a
+// trusted proxy saying so in X-Forwarded-Proto. Browsers ignore the header
+// over plain HTTP, and sending it from a development server on localhost
+// would pin localhost to HTTPS.
+func StrictTransportSecurity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hstsHeader != "" && isHTTPS(c.Request) {
+			c.Header("Strict-Transport-Security", hstsHeader)
+		}
+		c.Next()
+	}
+}
+
+// isHTTPS reports whether the client reached us over HTTPS.
+func isHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https") && fromTrustedProxy(r)
+}
+
+func fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+// Package i18n localizes the marketing site. English is the source
+// language: templates pass their English text through T, which looks it up
+// in the visitor's locale catalog (catalog/<code>.json, English text to
+// translation) and falls back to the English when there is no entry yet.
+// Localized pages are also served under /<code>/..., e.g. /ja/pricing.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/izinga/robustest-web/internal/app/site"
+)
+
+// Locale is one language the site is served in.
+type Locale struct {
+	Code string // URL prefix and html lang, e.g. "ja"
+	Name string // the language's own name, for the switcher
+	OG   string // og:locale, e.g. "ja_JP"
+}
+
+var (
+	English  = Locale{Code: "en", Name: "English", OG: "en_US"}
+	Japanese = Locale{Code: "ja", Name: "日本語", OG: "ja_JP"}
+	German   = Locale{Code: "de", Name: "Deutsch", OG: "de_DE"}
+
+	// Default is served without a prefix and is the x-default alternate.
+	Default = English
+	// Locales lists every supported locale, Default first.
+	Locales = []Locale{English, Japanese, German}
+)
+
+//go:embed catalog/*.json
+var catalogFiles embed.FS
+
+// catalogs maps locale code to its messages. English has none: the English
+// text is the message ID.
+var catalogs = map[string]map[string]string{}
+
+func init() {
+	for _, l := range Locales {
+		if l == Default {
+			continue
+		}
+		raw, err := catalogFiles.ReadFile("catalog/" + l.Code + ".json")
+		if err != nil {
+			panic("i18n: missing catalog for " + l.Code)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			panic("i18n: catalog/" + l.Code + ".json: " + err.Error())
+		}
+		catalogs[l.Code] = messages
+	}
+}
+
+// Lookup returns the supported locale with code.
+func Lookup(code string) (Locale, bool) {
+	for _, l := range Locales {
+		if l.Code == code {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// T translates the English msg into l, or returns it unchanged.
+func (l Locale) T(msg string) string {
+	if t, ok := catalogs[l.Code][msg]; ok && t != "" {
+		return t
+	}
+	return msg
+}
+
+// Path returns the URL of the unprefixed path p in l: "/pricing" becomes
+// "/ja/pricing". Paths without localized versions are returned unchanged.
+func (l Locale) Path(p string) string {
+	if l == Default || !IsLocalized(p) {
+		return p
+	}
+	if p == "/" {
+		return "/" + l.Code
+	}
+	return "/" + l.Code + p
+}
+
+// IsLocalized reports whether the unprefixed path p has localized versions
+// (a page registered with Localized set). Other pages (docs, contact,
+// legal, ...) exist in English only, and links to them stay unprefixed in
+// every locale.
+func IsLocalized(p string) bool {
+	page, ok := site.Lookup(p)
+	return ok && page.Localized
+}
+
+type ctxKey struct{}
+
+// WithLocale returns ctx carrying l for the templates rendered with it.
+func WithLocale(ctx context.Context, l Locale) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the locale set with WithLocale, or Default.
+func FromContext(ctx context.Context) Locale {
+	if l, ok := ctx.Value(ctxKey{}).(Locale); ok {
+		return l
+	}
+	return Default
+}
+
+// T translates msg into the locale carried by ctx.
+func T(ctx context.Context, msg string) string {
+	return FromContext(ctx).T(msg)
+}
+
+// Path localizes the unprefixed path p for the locale carried by ctx.
+func Path(ctx context.Context, p string) string {
+	return FromContext(ctx).Path(p)
+}
+
+// Negotiate picks the supported locale the visitor prefers from an
+// Accept-Language header, or Default. "de-AT" matches German; q=0 ranges
+// are ignored.
+func Negotiate(acceptLanguage string) Locale {
+	type pref struct {
+		locale Locale
+		q      float64
+	}
+	var prefs []pref
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if l, ok := Lookup(base); ok && q > 0 {
+			prefs = append(prefs, pref{l, q})
+		}
+	}
+	// Stable, so equal weights keep the header's order.
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+	if len(prefs) == 0 {
+		return Default
+	}
+	return prefs[0].locale
+}
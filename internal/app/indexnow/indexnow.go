@@ -0,0 +1,105 @@
+// Package indexnow tells search engines about changed URLs as soon as they
+// change (indexnow.org), instead of waiting for the next crawl. One
+// submission to the shared endpoint reaches every participating engine.
+package indexnow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint forwards submissions to all IndexNow engines.
+const DefaultEndpoint = "https://api.indexnow.org/indexnow"
+
+// maxURLs is the protocol's limit per submission.
+const maxURLs = 10000
+
+// Notifier submits changed URLs. Client is the real one; tests and
+// deployments without a key can substitute their own.
+type Notifier interface {
+	Notify(ctx context.Context, urls []string) error
+}
+
+// Client submits URLs of one host to an IndexNow endpoint.
+type Client struct {
+	Endpoint string
+	Host     string // e.g. "robustest.com"
+	// Key proves ownership of Host: the engines fetch KeyLocation and
+	// expect it to contain exactly Key.
+	Key         string
+	KeyLocation string
+	HTTP        *http.Client
+}
+
+// FromEnv returns a client for siteURL configured by INDEXNOW_KEY and
+// INDEXNOW_ENDPOINT, or nil when no key is set. The key file is expected at
+// siteURL/<key>.txt.
+func FromEnv(siteURL string) *Client {
+	key := os.Getenv("INDEXNOW_KEY")
+	if key == "" {
+		return nil
+	}
+	endpoint := os.Getenv("INDEXNOW_ENDPOINT")
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	host := siteURL
+	if u, err := url.Parse(siteURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return &Client{
+		Endpoint:    endpoint,
+		Host:        host,
+		Key:         key,
+		KeyLocation: strings.TrimSuffix(siteURL, "/") + "/" + key + ".txt",
+		HTTP:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Notify submits urls, in batches of the protocol's maximum.
+func (c *Client) Notify(ctx context.Context, urls []string) error {
+	for len(urls) > 0 {
+		n := min(len(urls), maxURLs)
+		if err := c.submit(ctx, urls[:n]); err != nil {
+			return err
+		}
+		urls = urls[n:]
+	}
+	return nil
+}
+
+func (c *Client) submit(ctx context.Context, urls []string) error {
+	body, err := json.Marshal(struct {
+		Host        string   `json:"host"`
+		Key         string   `json:"key"`
+		KeyLocation string   `json:"keyLocation"`
+		URLList     []string `json:"urlList"`
+	}{c.Host, c.Key, c.KeyLocation, urls})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// 200 OK and 202 Accepted (key validation pending) both mean received.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("indexnow: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
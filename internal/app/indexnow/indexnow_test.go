@@ -0,0 +1,66 @@
+package indexnow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type submission struct {
+	Host        string   `json:"host"`
+	Key         string   `json:"key"`
+	KeyLocation string   `json:"keyLocation"`
+	URLList     []string `json:"urlList"`
+}
+
+func TestNotifyBatches(t *testing.T) {
+	var got []submission
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s submission
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		got = append(got, s)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	t.Setenv("INDEXNOW_KEY", "abc123")
+	t.Setenv("INDEXNOW_ENDPOINT", srv.URL)
+	c := FromEnv("https://example.test/")
+	urls := make([]string, maxURLs+5)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.test/docs/p%d", i)
+	}
+	if err := c.Notify(context.Background(), urls); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || len(got[0].URLList) != maxURLs || len(got[1].URLList) != 5 {
+		t.Fatalf("submitted %d batches, want %d URLs then 5", len(got), maxURLs)
+	}
+	if s := got[0]; s.Host != "example.test" || s.Key != "abc123" || s.KeyLocation != "https://example.test/abc123.txt" {
+		t.Errorf("submission = %+v", s)
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "key not valid", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := &Client{Endpoint: srv.URL, Host: "example.test", Key: "k", HTTP: srv.Client()}
+	if err := c.Notify(context.Background(), []string{"https://example.test/"}); err == nil {
+		t.Error("403 was not reported")
+	}
+}
+
+func TestFromEnvWithoutKey(t *testing.T) {
+	t.Setenv("INDEXNOW_KEY", "")
+	if c := FromEnv("https://example.test"); c != nil {
+		t.Errorf("FromEnv = %+v without a key, want nil", c)
+	}
+}
@@ -0,0 +1,111 @@
+// Package lastmod remembers when each page of the site last changed, so
+// the sitemap's <lastmod> moves only when a page's content does. Pages are
+// fingerprinted at startup; a page whose hash differs from the stored one
+// is stamped as changed now, and the dates survive restarts and deploys in
+// a small JSON file.
+package lastmod
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is what is known about one URL path.
+type Entry struct {
+	Hash      string    `json:"hash"`
+	FirstSeen time.Time `json:"first_seen"`
+	Changed   time.Time `json:"changed"`
+}
+
+// Store holds the entries of one state file.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Entry
+}
+
+// Open loads the state file at path; a missing file is an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, entries: map[string]Entry{}}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &s.entries); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// OpenFromEnv opens SITEMAP_STATE_FILE (default ./data/lastmod.json).
+func OpenFromEnv() (*Store, error) {
+	path := os.Getenv("SITEMAP_STATE_FILE")
+	if path == "" {
+		path = "./data/lastmod.json"
+	}
+	return Open(path)
+}
+
+// Observe records content as the current version of path and returns its
+// entry: new paths are first seen now, and a changed hash moves Changed to
+// now. Call Save to persist.
+func (s *Store) Observe(path string, content []byte, now time.Time) Entry {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[path]
+	switch {
+	case !ok:
+		e = Entry{Hash: hash, FirstSeen: now, Changed: now}
+	case e.Hash != hash:
+		e.Hash, e.Changed = hash, now
+	}
+	s.entries[path] = e
+	return e
+}
+
+// Get returns the entry for path.
+func (s *Store) Get(path string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[path]
+	return e, ok
+}
+
+// Save writes the state file, replacing the old one atomically.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".lastmod-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
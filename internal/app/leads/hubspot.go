@@ -0,0 +1,103 @@
+package leads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultHubSpotBase = "https://api.hubapi.com"
+
+// HubSpot creates (or updates, when the email already exists) a contact
+// through the CRM v3 contacts API using a private-app access token.
+//
+// Standard properties are filled from the form; lead type and attribution
+// go to custom contact properties that must exist in the portal:
+// robustest_lead_type, utm_source, utm_medium, utm_campaign, utm_term,
+// utm_content, robustest_referrer, robustest_landing_page and
+// robustest_source_page. Qualification answers go to robustest_segment and
+// robustest_<name> for each Qualification field (robustest_device_count,
+// robustest_platforms, ..., robustest_lab_tier).
+type HubSpot struct {
+	BaseURL string // default https://api.hubapi.com; overridden in tests
+	Token   string
+	Client  *http.Client
+}
+
+// Name implements Sink.
+func (h *HubSpot) Name() string { return "hubspot" }
+
+// hubSpotProperties maps a lead onto HubSpot contact properties.
+func hubSpotProperties(lead Lead) map[string]string {
+	first, last := SplitName(lead.Name)
+	props := map[string]string{
+		"email":               lead.Email,
+		"firstname":           first,
+		"lastname":            last,
+		"company":             lead.Company,
+		"phone":               lead.Phone,
+		"message":             lead.Message,
+		"robustest_lead_type": lead.Type,
+		"robustest_segment":   lead.Segment(),
+	}
+	custom := map[string]string{
+		"referrer":     "robustest_referrer",
+		"landing_page": "robustest_landing_page",
+		"source_page":  "robustest_source_page",
+	}
+	for k, v := range lead.Attribution.Fields() {
+		if name, ok := custom[k]; ok {
+			k = name
+		}
+		props[k] = v
+	}
+	for k, v := range lead.Qualification.Fields() {
+		props["robustest_"+k] = v
+	}
+	for k, v := range props {
+		if v == "" {
+			delete(props, k)
+		}
+	}
+	return props
+}
+
+// Deliver implements Sink.
+func (h *HubSpot) Deliver(ctx context.Context, lead Lead) error {
+	base := strings.TrimRight(h.BaseURL, "/")
+	if base == "" {
+		base = defaultHubSpotBase
+	}
+	body, err := json.Marshal(map[string]any{"properties": hubSpotProperties(lead)})
+	if err != nil {
+		return err
+	}
+
+	err = h.send(ctx, http.MethodPost, base+"/crm/v3/objects/contacts", body)
+	var se *StatusError
+	if errors.As(err, &se) && se.Status == http.StatusConflict {
+		// The contact already exists: update it in place, keyed by email.
+		return h.send(ctx, http.MethodPatch,
+			base+"/crm/v3/objects/contacts/"+url.PathEscape(lead.Email)+"?idProperty=email", body)
+	}
+	return err
+}
+
+func (h *HubSpot) send(ctx context.Context, method, target string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+h.Token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := do(h.Client, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
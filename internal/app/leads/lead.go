@@ -0,0 +1,110 @@
+// Package leads carries accepted contact-form submissions out of the web
+// handler: the Lead record itself and the sinks that deliver it to CRMs, so
+// sales never has to re-type a lead from the notification email.
+package leads
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Lead types. The contact form sends lead_type=partner from /partners;
+// everything else is a demo request.
+const (
+	TypeDemo    = "demo"
+	TypePartner = "partner"
+)
+
+// Lead is one accepted contact-form submission.
+type Lead struct {
+	ID            string        `json:"id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Type          string        `json:"type"`
+	Name          string        `json:"name"`
+	Email         string        `json:"email"`
+	Company       string        `json:"company,omitempty"`
+	Phone         string        `json:"phone,omitempty"`
+	Message       string        `json:"message,omitempty"`
+	Qualification Qualification `json:"qualification"`
+	Attribution   Attribution   `json:"attribution"`
+	// VerifiedAt is when the visitor clicked the link in the confirmation
+	// email, proving the address is theirs; nil until then.
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	// AnonymizedAt is when the retention policy stripped the lead's
+	// personal details; what remains only feeds the reports.
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+}
+
+// Anonymize removes everything that identifies the person behind the lead,
+// keeping its type, company, qualification and attribution for reporting.
+func (l *Lead) Anonymize(at time.Time) {
+	at = at.UTC()
+	l.Name, l.Email, l.Phone, l.Message = "", "", "", ""
+	l.AnonymizedAt = &at
+}
+
+// Attribution records where a lead came from: campaign parameters and
+// referrer from the visitor's first landing, and the page they were on when
+// they decided to get in touch.
+type Attribution struct {
+	UTMSource   string `json:"utm_source,omitempty"`
+	UTMMedium   string `json:"utm_medium,omitempty"`
+	UTMCampaign string `json:"utm_campaign,omitempty"`
+	UTMTerm     string `json:"utm_term,omitempty"`
+	UTMContent  string `json:"utm_content,omitempty"`
+	Referrer    string `json:"referrer,omitempty"`
+	LandingPage string `json:"landing_page,omitempty"`
+	SourcePage  string `json:"source_page,omitempty"`
+}
+
+// Fields returns the attribution as CRM-style field names, omitting empty
+// values. Sinks use these names directly or map them to their own.
+func (a Attribution) Fields() map[string]string {
+	all := map[string]string{
+		"utm_source":   a.UTMSource,
+		"utm_medium":   a.UTMMedium,
+		"utm_campaign": a.UTMCampaign,
+		"utm_term":     a.UTMTerm,
+		"utm_content":  a.UTMContent,
+		"referrer":     a.Referrer,
+		"landing_page": a.LandingPage,
+		"source_page":  a.SourcePage,
+	}
+	out := make(map[string]string, len(all))
+	for k, v := range all {
+		if v != "" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// New stamps a lead with a fresh ID and creation time.
+func New(leadType string) Lead {
+	if leadType != TypePartner {
+		leadType = TypeDemo
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return Lead{
+		ID:        hex.EncodeToString(b),
+		CreatedAt: time.Now().UTC(),
+		Type:      leadType,
+	}
+}
+
+// SplitName splits a single "Name" field into first and last name the way
+// CRMs expect: the last word is the surname, the rest the given name.
+func SplitName(name string) (first, last string) {
+	parts := strings.Fields(name)
+	switch len(parts) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", parts[0]
+	default:
+		return strings.Join(parts[:len(parts)-1], " "), parts[len(parts)-1]
+	}
+}
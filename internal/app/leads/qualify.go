@@ -0,0 +1,157 @@
+package leads
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Option is one allowed answer to a qualification question.
+type Option struct {
+	Value string
+	Label string
+}
+
+// Answers the qualification steps of the contact form accept. The values
+// are stable identifiers stored with the lead and sent to CRMs.
+var (
+	PlatformOptions = []Option{
+		{"android", "Android"},
+		{"ios", "iOS"},
+		{"tizen", "Samsung Tizen"},
+		{"webos", "LG webOS"},
+		{"roku", "Roku"},
+		{"appletv", "Apple TV"},
+		{"androidtv", "Android TV / Fire TV"},
+		{"stb", "Set-top boxes & consoles"},
+	}
+	FrameworkOptions = []Option{
+		{"appium", "Appium"},
+		{"espresso", "Espresso"},
+		{"xcuitest", "XCUITest"},
+		{"selenium", "Selenium"},
+		{"maestro", "Maestro"},
+		{"manual", "Manual testing only"},
+	}
+	DeploymentOptions = []Option{
+		{"on-prem", "On our premises"},
+		{"air-gapped", "Air-gapped, fully offline"},
+		{"multi-site", "Multiple sites"},
+	}
+	TimelineOptions = []Option{
+		{"now", "This month"},
+		{"quarter", "This quarter"},
+		{"half", "Within six months"},
+		{"exploring", "Just exploring"},
+	}
+)
+
+// ValidOption reports whether v is one of opts' values.
+func ValidOption(opts []Option, v string) bool {
+	for _, o := range opts {
+		if o.Value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Labels returns the display labels for values, in the options' order.
+func Labels(opts []Option, values []string) []string {
+	var out []string
+	for _, o := range opts {
+		for _, v := range values {
+			if o.Value == v {
+				out = append(out, o.Label)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Qualification is what the visitor told us about the lab they need.
+type Qualification struct {
+	DeviceCount int      `json:"device_count,omitempty"`
+	Platforms   []string `json:"platforms,omitempty"`
+	Frameworks  []string `json:"frameworks,omitempty"`
+	Deployment  string   `json:"deployment,omitempty"`
+	Timeline    string   `json:"timeline,omitempty"`
+	Tier        string   `json:"tier,omitempty"`
+}
+
+// Fields returns the qualification as CRM-style field names, omitting
+// empty values; lists are joined with ";" as CRMs expect for multi-selects.
+func (q Qualification) Fields() map[string]string {
+	all := map[string]string{
+		"platforms":  strings.Join(q.Platforms, ";"),
+		"frameworks": strings.Join(q.Frameworks, ";"),
+		"deployment": q.Deployment,
+		"timeline":   q.Timeline,
+		"lab_tier":   q.Tier,
+	}
+	if q.DeviceCount > 0 {
+		all["device_count"] = strconv.Itoa(q.DeviceCount)
+	}
+	out := make(map[string]string, len(all))
+	for k, v := range all {
+		if v != "" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Tier is an indicative lab size. RobusTest is licensed by device seats
+// (see /pricing); tiers only bucket seat counts so sales can triage.
+type Tier struct {
+	Name     string
+	MinSeats int
+	MaxSeats int // 0 = no upper bound
+}
+
+// Tiers in ascending size.
+var Tiers = []Tier{
+	{"Starter lab", 1, 10},
+	{"Team lab", 11, 30},
+	{"Department lab", 31, 100},
+	{"Enterprise lab", 101, 0},
+}
+
+// TierFor returns the tier a device-seat count falls in.
+func TierFor(devices int) Tier {
+	for _, t := range Tiers {
+		if t.MaxSeats == 0 || devices <= t.MaxSeats {
+			return t
+		}
+	}
+	return Tiers[len(Tiers)-1]
+}
+
+// String renders the tier with its seat range, e.g. "Team lab (11–30 device seats)".
+func (t Tier) String() string {
+	if t.MaxSeats == 0 {
+		return fmt.Sprintf("%s (%d+ device seats)", t.Name, t.MinSeats)
+	}
+	return fmt.Sprintf("%s (%d–%d device seats)", t.Name, t.MinSeats, t.MaxSeats)
+}
+
+// Segments decide which inbox a lead is routed to.
+const (
+	SegmentStandard   = "standard"
+	SegmentEnterprise = "enterprise"
+	SegmentPartner    = "partner"
+)
+
+// Segment classifies a lead for routing: partners to the partner team,
+// large or offline/multi-site labs to enterprise sales, the rest standard.
+func (l Lead) Segment() string {
+	if l.Type == TypePartner {
+		return SegmentPartner
+	}
+	q := l.Qualification
+	if q.DeviceCount > 30 || q.Deployment == "air-gapped" || q.Deployment == "multi-site" {
+		return SegmentEnterprise
+	}
+	return SegmentStandard
+}
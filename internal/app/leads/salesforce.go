@@ -0,0 +1,85 @@
+package leads
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultWebToLeadURL = "https://webto.salesforce.com/servlet/servlet.WebToLead?encoding=UTF-8"
+
+// Salesforce posts each lead to the org's Web-to-Lead endpoint. Only the
+// org ID is needed; no API user. Attribution lands in custom lead fields,
+// which Web-to-Lead addresses by field ID: FieldMap maps attribution names
+// (utm_source, referrer, source_page, ...), qualification names
+// (device_count, platforms, ..., lab_tier) and lead_type and segment to
+// those IDs, e.g.
+// SALESFORCE_FIELD_MAP="utm_source=00N5g00000AbCdE,lead_type=00N5g00000FgHiJ".
+//
+// Web-to-Lead answers 200 even when it drops a lead, so failures inside
+// Salesforce show up only in its admin email, not here.
+type Salesforce struct {
+	URL      string // default webto.salesforce.com; overridden in tests
+	OrgID    string
+	FieldMap map[string]string
+	Client   *http.Client
+}
+
+// Name implements Sink.
+func (s *Salesforce) Name() string { return "salesforce" }
+
+// salesforceForm maps a lead onto Web-to-Lead form fields.
+func (s *Salesforce) salesforceForm(lead Lead) url.Values {
+	first, last := SplitName(lead.Name)
+	company := lead.Company
+	if company == "" {
+		company = "[not provided]" // Company is required on Salesforce leads
+	}
+	source := "Website"
+	if lead.Type == TypePartner {
+		source = "Partner Referral"
+	}
+	form := url.Values{
+		"oid":         {s.OrgID},
+		"first_name":  {first},
+		"last_name":   {last},
+		"email":       {lead.Email},
+		"company":     {company},
+		"phone":       {lead.Phone},
+		"description": {lead.Message},
+		"lead_source": {source},
+	}
+	extra := lead.Attribution.Fields()
+	for k, v := range lead.Qualification.Fields() {
+		extra[k] = v
+	}
+	extra["lead_type"] = lead.Type
+	extra["segment"] = lead.Segment()
+	for k, v := range extra {
+		if id := s.FieldMap[k]; id != "" {
+			form.Set(id, v)
+		}
+	}
+	return form
+}
+
+// Deliver implements Sink.
+func (s *Salesforce) Deliver(ctx context.Context, lead Lead) error {
+	target := s.URL
+	if target == "" {
+		target = defaultWebToLeadURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target,
+		strings.NewReader(s.salesforceForm(lead).Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := do(s.Client, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,177 @@
+package leads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink delivers a lead to one downstream system (a CRM or a webhook).
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, lead Lead) error
+}
+
+// StatusError is a non-2xx answer from a sink's endpoint. 429 and 5xx are
+// worth retrying; any other 4xx means the request itself is wrong and
+// retrying would only repeat the failure.
+type StatusError struct {
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.Status, e.Body)
+}
+
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Status == http.StatusTooManyRequests || se.Status >= 500
+	}
+	return true // transport errors and timeouts
+}
+
+// do sends req and turns a non-2xx response into a *StatusError.
+func do(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, &StatusError{Status: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return resp, nil
+}
+
+// Dispatcher fans each lead out to every configured sink in the background,
+// retrying transient failures with exponential backoff.
+type Dispatcher struct {
+	sinks    []Sink
+	attempts int
+	backoff  time.Duration // delay before the first retry; doubles each time
+	timeout  time.Duration // per attempt
+
+	wg sync.WaitGroup
+}
+
+// NewDispatcher returns a dispatcher for sinks with the default retry policy
+// (5 attempts, 2s initial backoff, 15s per attempt).
+func NewDispatcher(sinks ...Sink) *Dispatcher {
+	return &Dispatcher{sinks: sinks, attempts: 5, backoff: 2 * time.Second, timeout: 15 * time.Second}
+}
+
+// Sinks returns the configured sinks.
+func (d *Dispatcher) Sinks() []Sink {
+	return d.sinks
+}
+
+// Dispatch queues lead for delivery to every sink and returns immediately.
+func (d *Dispatcher) Dispatch(lead Lead) {
+	for _, s := range d.sinks {
+		d.wg.Add(1)
+		go func(s Sink) {
+			defer d.wg.Done()
+			d.deliver(s, lead)
+		}(s)
+	}
+}
+
+func (d *Dispatcher) deliver(s Sink, lead Lead) {
+	delay := d.backoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+		err := s.Deliver(ctx, lead)
+		cancel()
+		if err == nil {
+			log.Printf("leads: delivered %s to %s", lead.ID, s.Name())
+			return
+		}
+		if !retryable(err) || attempt >= d.attempts {
+			log.Printf("leads: giving up on %s for %s after %d attempt(s): %v", s.Name(), lead.ID, attempt, err)
+			return
+		}
+		log.Printf("leads: %s delivery of %s failed (attempt %d/%d), retrying in %s: %v",
+			s.Name(), lead.ID, attempt, d.attempts, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Wait blocks until in-flight deliveries finish or ctx is done, so a
+// graceful shutdown doesn't drop leads mid-retry.
+func (d *Dispatcher) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DispatcherFromEnv builds the dispatcher from whichever sinks are
+// configured; with none configured it dispatches nowhere.
+//
+//	CRM_WEBHOOK_URL, CRM_WEBHOOK_SECRET      signed JSON webhook
+//	HUBSPOT_ACCESS_TOKEN, HUBSPOT_API_BASE   HubSpot contacts API
+//	SALESFORCE_OID, SALESFORCE_FIELD_MAP     Salesforce Web-to-Lead
+//	CRM_RETRY_ATTEMPTS                       attempts per sink (default 5)
+func DispatcherFromEnv() *Dispatcher {
+	client := &http.Client{Timeout: 15 * time.Second}
+	var sinks []Sink
+	if u := os.Getenv("CRM_WEBHOOK_URL"); u != "" {
+		secret := os.Getenv("CRM_WEBHOOK_SECRET")
+		if secret == "" {
+			log.Printf("Warning: CRM_WEBHOOK_SECRET is not set; webhook deliveries are unsigned and the receiver cannot tell them from forgeries")
+		}
+		sinks = append(sinks, &Webhook{URL: u, Secret: secret, Client: client})
+	}
+	if token := os.Getenv("HUBSPOT_ACCESS_TOKEN"); token != "" {
+		sinks = append(sinks, &HubSpot{BaseURL: os.Getenv("HUBSPOT_API_BASE"), Token: token, Client: client})
+	}
+	if oid := os.Getenv("SALESFORCE_OID"); oid != "" {
+		sinks = append(sinks, &Salesforce{
+			URL:      os.Getenv("SALESFORCE_WEB_TO_LEAD_URL"),
+			OrgID:    oid,
+			FieldMap: parseFieldMap(os.Getenv("SALESFORCE_FIELD_MAP")),
+			Client:   client,
+		})
+	}
+	d := NewDispatcher(sinks...)
+	if v := os.Getenv("CRM_RETRY_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			d.attempts = n
+		}
+	}
+	for _, s := range sinks {
+		log.Printf("leads: delivering to %s", s.Name())
+	}
+	return d
+}
+
+// parseFieldMap parses "utm_source=00N1,utm_medium=00N2" into a map.
+func parseFieldMap(v string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && k != "" && val != "" {
+			m[k] = val
+		}
+	}
+	return m
+}
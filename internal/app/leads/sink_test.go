@@ -0,0 +1,272 @@
+package leads
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testLead is a partner lead with full attribution.
+func testLead() Lead {
+	l := New(TypePartner)
+	l.Name = "Ada Byron Lovelace"
+	l.Email = "ada@example.com"
+	l.Company = "Analytical Engines"
+	l.Message = "We resell device labs."
+	l.Qualification = Qualification{DeviceCount: 40, Platforms: []string{"android", "ios"}}
+	l.Attribution = Attribution{
+		UTMSource:   "newsletter",
+		UTMCampaign: "spring",
+		Referrer:    "https://news.example.com/post",
+		LandingPage: "/partners",
+		SourcePage:  "/platform/tv-testing",
+	}
+	return l
+}
+
+func TestWebhookSignature(t *testing.T) {
+	const secret = "s3cret"
+	lead := testLead()
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	w := &Webhook{URL: srv.URL, Secret: secret, Client: srv.Client()}
+	if err := w.Deliver(context.Background(), lead); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	ts := got.Header.Get("X-Robustest-Timestamp")
+	if _, err := strconv.ParseInt(ts, 10, 64); err != nil {
+		t.Fatalf("X-Robustest-Timestamp = %q, want unix seconds", ts)
+	}
+	if sig, want := got.Header.Get("X-Robustest-Signature"), "sha256="+SignWebhook(secret, ts, body); sig != want {
+		t.Errorf("X-Robustest-Signature = %q, want %q", sig, want)
+	}
+	if sig := got.Header.Get("X-Robustest-Signature"); sig == "sha256="+SignWebhook("other", ts, body) {
+		t.Error("signature does not depend on the secret")
+	}
+	if id := got.Header.Get("X-Robustest-Delivery"); id != lead.ID {
+		t.Errorf("X-Robustest-Delivery = %q, want %q", id, lead.ID)
+	}
+	var sent Lead
+	if err := json.Unmarshal(body, &sent); err != nil || sent.Email != lead.Email || sent.Attribution != lead.Attribution {
+		t.Errorf("body = %s, want the lead as JSON", body)
+	}
+}
+
+func TestWebhookUnsignedWithoutSecret(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer srv.Close()
+
+	w := &Webhook{URL: srv.URL, Client: srv.Client()}
+	if err := w.Deliver(context.Background(), testLead()); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if got.Get("X-Robustest-Signature") != "" || got.Get("X-Robustest-Timestamp") != "" {
+		t.Errorf("signed without a secret: %v", got)
+	}
+}
+
+func TestHubSpotProperties(t *testing.T) {
+	var props map[string]string
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/crm/v3/objects/contacts" {
+			t.Errorf("request %s %s", r.Method, r.URL)
+		}
+		auth = r.Header.Get("Authorization")
+		var body struct {
+			Properties map[string]string `json:"properties"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		props = body.Properties
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	h := &HubSpot{BaseURL: srv.URL, Token: "tok", Client: srv.Client()}
+	if err := h.Deliver(context.Background(), testLead()); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if auth != "Bearer tok" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	want := map[string]string{
+		"email":                  "ada@example.com",
+		"firstname":              "Ada Byron",
+		"lastname":               "Lovelace",
+		"company":                "Analytical Engines",
+		"robustest_lead_type":    TypePartner,
+		"robustest_segment":      SegmentPartner,
+		"utm_source":             "newsletter",
+		"utm_campaign":           "spring",
+		"robustest_referrer":     "https://news.example.com/post",
+		"robustest_landing_page": "/partners",
+		"robustest_source_page":  "/platform/tv-testing",
+		"robustest_device_count": "40",
+		"robustest_platforms":    "android;ios",
+	}
+	for k, v := range want {
+		if props[k] != v {
+			t.Errorf("property %s = %q, want %q", k, props[k], v)
+		}
+	}
+	for _, k := range []string{"phone", "utm_medium", "robustest_timeline"} {
+		if _, ok := props[k]; ok {
+			t.Errorf("empty property %s was sent", k)
+		}
+	}
+}
+
+func TestHubSpotUpdatesExistingContact(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.RequestURI())
+		if r.Method == http.MethodPost {
+			http.Error(w, `{"message":"Contact already exists"}`, http.StatusConflict)
+		}
+	}))
+	defer srv.Close()
+
+	h := &HubSpot{BaseURL: srv.URL, Token: "tok", Client: srv.Client()}
+	if err := h.Deliver(context.Background(), testLead()); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	want := "PATCH /crm/v3/objects/contacts/ada@example.com?idProperty=email"
+	if len(methods) != 2 || methods[1] != want {
+		t.Errorf("requests = %q, want POST then %q", methods, want)
+	}
+}
+
+func TestSalesforceForm(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+	}))
+	defer srv.Close()
+
+	s := &Salesforce{
+		URL:   srv.URL,
+		OrgID: "00D000000000001",
+		FieldMap: parseFieldMap("utm_source=00N1,referrer=00N2,source_page=00N3,lead_type=00N4,segment=00N5," +
+			"device_count=00N6,utm_medium=00N7"),
+		Client: srv.Client(),
+	}
+	if err := s.Deliver(context.Background(), testLead()); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	want := map[string]string{
+		"oid":         "00D000000000001",
+		"first_name":  "Ada Byron",
+		"last_name":   "Lovelace",
+		"email":       "ada@example.com",
+		"company":     "Analytical Engines",
+		"lead_source": "Partner Referral",
+		"00N1":        "newsletter",
+		"00N2":        "https://news.example.com/post",
+		"00N3":        "/platform/tv-testing",
+		"00N4":        TypePartner,
+		"00N5":        SegmentPartner,
+		"00N6":        "40",
+	}
+	for k, v := range want {
+		if form.Get(k) != v {
+			t.Errorf("field %s = %q, want %q", k, form.Get(k), v)
+		}
+	}
+	if _, ok := form["00N7"]; ok {
+		t.Error("mapped but empty utm_medium was sent")
+	}
+	if _, ok := form["utm_campaign"]; ok {
+		t.Error("unmapped attribution was sent under its own name")
+	}
+}
+
+func TestSalesforceDemoLead(t *testing.T) {
+	lead := testLead()
+	lead.Type = TypeDemo
+	lead.Company = ""
+	form := (&Salesforce{OrgID: "x"}).salesforceForm(lead)
+	if got := form.Get("lead_source"); got != "Website" {
+		t.Errorf("lead_source = %q, want Website", got)
+	}
+	if got := form.Get("company"); got != "[not provided]" {
+		t.Errorf("company = %q, want the placeholder", got)
+	}
+}
+
+// flaky answers each request with the next status in statuses.
+func flaky(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n > len(statuses) {
+			t.Errorf("unexpected attempt %d", n)
+			return
+		}
+		w.WriteHeader(statuses[n-1])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestDispatcherRetries5xx(t *testing.T) {
+	srv, calls := flaky(t, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+	d := NewDispatcher(&Webhook{URL: srv.URL, Secret: "x", Client: srv.Client()})
+	d.backoff = time.Millisecond
+	d.Dispatch(testLead())
+	if err := d.Wait(ctxTimeout(t)); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("attempts = %d, want 3 (two 5xx, then success)", n)
+	}
+}
+
+func TestDispatcherGivesUpOn4xx(t *testing.T) {
+	srv, calls := flaky(t, http.StatusBadRequest)
+	d := NewDispatcher(&Salesforce{URL: srv.URL, OrgID: "x", Client: srv.Client()})
+	d.backoff = time.Millisecond
+	d.Dispatch(testLead())
+	if err := d.Wait(ctxTimeout(t)); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("attempts = %d, want 1: a 4xx is not retried", n)
+	}
+}
+
+func TestDispatcherStopsAfterAttempts(t *testing.T) {
+	srv, calls := flaky(t, 500, 500, 500)
+	d := NewDispatcher(&HubSpot{BaseURL: srv.URL, Token: "tok", Client: srv.Client()})
+	d.backoff = time.Millisecond
+	d.attempts = 3
+	d.Dispatch(testLead())
+	if err := d.Wait(ctxTimeout(t)); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("attempts = %d, want 3", n)
+	}
+}
+
+func ctxTimeout(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
@@ -0,0 +1,269 @@
+package leads
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound means no stored lead has the given ID.
+var ErrNotFound = errors.New("leads: not found")
+
+// Store keeps accepted leads as JSON lines in a single file. Lead volume is
+// a handful a day, so a flat file beats running a database next to the
+// site; every write holds the mutex for the whole read-modify-write, and
+// rewrites replace the file with an atomic rename.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore returns a store backed by path; the file and its directory are
+// created on first write.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// StoreFromEnv returns the store at LEADS_FILE (default ./data/leads.jsonl).
+func StoreFromEnv() *Store {
+	path := os.Getenv("LEADS_FILE")
+	if path == "" {
+		path = "./data/leads.jsonl"
+	}
+	return NewStore(path)
+}
+
+// Append records a new lead.
+func (s *Store) Append(lead Lead) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	raw, err := json.Marshal(lead)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(raw, '\n'))
+	return err
+}
+
+// All returns every stored lead, oldest first.
+func (s *Store) All() ([]Lead, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// ByEmail returns every stored lead submitted with email.
+func (s *Store) ByEmail(email string) ([]Lead, error) {
+	all, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+	var out []Lead
+	for _, lead := range all {
+		if strings.EqualFold(lead.Email, email) {
+			out = append(out, lead)
+		}
+	}
+	return out, nil
+}
+
+// MarkVerified records that the lead's email address was confirmed. A lead
+// verified earlier keeps its first verification time.
+func (s *Store) MarkVerified(id string, at time.Time) (Lead, error) {
+	var verified Lead
+	err := s.update(func(all []Lead) ([]Lead, error) {
+		for i := range all {
+			if all[i].ID != id {
+				continue
+			}
+			if all[i].VerifiedAt == nil {
+				at := at.UTC()
+				all[i].VerifiedAt = &at
+			}
+			verified = all[i]
+			return all, nil
+		}
+		return nil, ErrNotFound
+	})
+	return verified, err
+}
+
+// DeleteByEmail removes every lead submitted with email and returns how
+// many there were.
+func (s *Store) DeleteByEmail(email string) (int, error) {
+	deleted := 0
+	err := s.update(func(all []Lead) ([]Lead, error) {
+		kept := all[:0]
+		for _, lead := range all {
+			if strings.EqualFold(lead.Email, email) {
+				deleted++
+				continue
+			}
+			kept = append(kept, lead)
+		}
+		return kept, nil
+	})
+	return deleted, err
+}
+
+// Purge applies the retention policy to leads created before cutoff:
+// with anonymize they lose their personal details (see Lead.Anonymize),
+// otherwise they are deleted. It returns how many leads changed.
+func (s *Store) Purge(cutoff time.Time, anonymize bool) (int, error) {
+	changed := 0
+	err := s.update(func(all []Lead) ([]Lead, error) {
+		kept := all[:0]
+		for _, lead := range all {
+			switch {
+			case !lead.CreatedAt.Before(cutoff), anonymize && lead.AnonymizedAt != nil:
+			case anonymize:
+				lead.Anonymize(time.Now())
+				changed++
+			default:
+				changed++
+				continue
+			}
+			kept = append(kept, lead)
+		}
+		return kept, nil
+	})
+	return changed, err
+}
+
+// update rewrites the file with what fn returns, under the lock. If fn
+// fails nothing is written.
+func (s *Store) update(fn func([]Lead) ([]Lead, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all, err = fn(all)
+	if err != nil {
+		return err
+	}
+	return s.write(all)
+}
+
+func (s *Store) write(all []Lead) error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".leads-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, lead := range all {
+		if err := enc.Encode(lead); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *Store) read() ([]Lead, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Lead
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var lead Lead
+		if err := json.Unmarshal(sc.Bytes(), &lead); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.path, n, err)
+		}
+		out = append(out, lead)
+	}
+	return out, sc.Err()
+}
+
+// SourceCount is one row of the leads-by-source-page report.
+type SourceCount struct {
+	SourcePage string `json:"source_page"`
+	Demo       int    `json:"demo"`
+	Partner    int    `json:"partner"`
+	Total      int    `json:"total"`
+}
+
+// DirectSource labels leads that opened /contact without coming from
+// another page on the site (typed URL, bookmark, external link).
+const DirectSource = "(direct)"
+
+// SourceReport counts leads created at or after since by the page the
+// visitor was on before the contact form, busiest page first.
+func SourceReport(all []Lead, since time.Time) []SourceCount {
+	bySource := make(map[string]*SourceCount)
+	for _, lead := range all {
+		if lead.CreatedAt.Before(since) {
+			continue
+		}
+		src := lead.Attribution.SourcePage
+		if src == "" {
+			src = DirectSource
+		}
+		row := bySource[src]
+		if row == nil {
+			row = &SourceCount{SourcePage: src}
+			bySource[src] = row
+		}
+		if lead.Type == TypePartner {
+			row.Partner++
+		} else {
+			row.Demo++
+		}
+		row.Total++
+	}
+	out := make([]SourceCount, 0, len(bySource))
+	for _, row := range bySource {
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].SourcePage < out[j].SourcePage
+	})
+	return out
+}
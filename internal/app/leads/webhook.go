@@ -0,0 +1,68 @@
+package leads
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook POSTs each lead as JSON to a URL of the receiver's choosing
+// (Zapier, n8n, an internal service). When Secret is set the body is signed
+// so the receiver can verify it came from us:
+//
+//	X-Robustest-Timestamp: <unix seconds>
+//	X-Robustest-Signature: sha256=<hex HMAC-SHA256(secret, timestamp + "." + body)>
+//
+// Binding the timestamp into the signature lets receivers reject replays.
+// X-Robustest-Delivery carries the lead ID, stable across retries, for
+// de-duplication.
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// Name implements Sink.
+func (w *Webhook) Name() string { return "webhook" }
+
+// Deliver implements Sink.
+func (w *Webhook) Deliver(ctx context.Context, lead Lead) error {
+	body, err := json.Marshal(lead)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "robustest-web-leads")
+	req.Header.Set("X-Robustest-Delivery", lead.ID)
+	if w.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Robustest-Timestamp", ts)
+		req.Header.Set("X-Robustest-Signature", "sha256="+SignWebhook(w.Secret, ts, body))
+	}
+	resp, err := do(w.Client, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SignWebhook computes the hex signature a receiver should compare (in
+// constant time) against X-Robustest-Signature.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,234 @@
+// Package logfile is an append-only log file that rotates itself by size
+// and age and can drop rotated segments past a retention period. It backs
+// the contact-form log, which holds personal data and so must neither grow
+// forever nor be readable by other users on the host.
+package logfile
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedFormat stamps rotated segments: contact_form.log.20261018-120000.
+const rotatedFormat = "20060102-150405"
+
+// File is an io.Writer for log.Logger. Once the current file reaches
+// MaxBytes, or has been written to for longer than MaxAge, it is renamed
+// with a timestamp suffix and a fresh file is started. Zero limits disable
+// that kind of rotation.
+type File struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxAge   time.Duration
+
+	f      *os.File
+	size   int64
+	opened time.Time
+}
+
+// Open opens (or creates) path for appending with owner-only permissions,
+// tightening them on a file created by an older build.
+func Open(path string, maxBytes int64, maxAge time.Duration) (*File, error) {
+	l := &File{path: path, maxBytes: maxBytes, maxAge: maxAge}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *File) open() error {
+	if dir := filepath.Dir(l.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size, l.opened = f, fi.Size(), time.Now()
+	if fi.Size() > 0 {
+		// Entries already in the file are as old as its last write at best.
+		l.opened = fi.ModTime()
+	}
+	return nil
+}
+
+// Write appends p, rotating first if the current file is full or too old.
+func (l *File) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.size > 0 && ((l.maxBytes > 0 && l.size+int64(len(p)) > l.maxBytes) ||
+		(l.maxAge > 0 && time.Since(l.opened) > l.maxAge)) {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// Rotate starts a new file now.
+func (l *File) Rotate() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rotate()
+}
+
+func (l *File) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	if l.size > 0 {
+		if err := os.Rename(l.path, l.path+"."+time.Now().UTC().Format(rotatedFormat)); err != nil {
+			return err
+		}
+	}
+	return l.open()
+}
+
+// Purge deletes rotated segments whose last entry is older than before and
+// returns how many it removed. The current file is rotated first if all of
+// it is that old, so a quiet log is not kept past retention either.
+func (l *File) Purge(before time.Time) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.size > 0 && l.opened.Before(before) {
+		if fi, err := l.f.Stat(); err == nil && fi.ModTime().Before(before) {
+			if err := l.rotate(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	segments, err := filepath.Glob(l.path + ".*")
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	var errs []error
+	for _, seg := range segments {
+		if _, err := time.Parse(rotatedFormat, strings.TrimPrefix(seg, l.path+".")); err != nil {
+			continue // not one of ours
+		}
+		fi, err := os.Stat(seg)
+		if err != nil || !fi.ModTime().Before(before) {
+			continue
+		}
+		if err := os.Remove(seg); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// Scrub removes the lines drop reports true for from the current file and
+// every rotated segment, for erasure requests, and returns how many it
+// removed. Files keep their modification times, so rotation and Purge
+// still see how old their entries are.
+func (l *File) Scrub(drop func(line string) bool) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.f.Close(); err != nil {
+		return 0, err
+	}
+	removed, err := scrubFile(l.path, drop)
+	opened := l.opened
+	if err := l.open(); err != nil {
+		return removed, err
+	}
+	l.opened = opened
+	if err != nil {
+		return removed, err
+	}
+
+	segments, err := filepath.Glob(l.path + ".*")
+	if err != nil {
+		return removed, err
+	}
+	var errs []error
+	for _, seg := range segments {
+		if _, err := time.Parse(rotatedFormat, strings.TrimPrefix(seg, l.path+".")); err != nil {
+			continue // not one of ours
+		}
+		n, err := scrubFile(seg, drop)
+		removed += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// scrubFile rewrites path without the lines drop reports true for. The new
+// version replaces the old with an atomic rename and keeps its
+// modification time.
+func scrubFile(path string, drop func(line string) bool) (int, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	var kept []byte
+	removed := 0
+	for _, line := range strings.SplitAfter(string(raw), "\n") {
+		if line != "" && drop(strings.TrimSuffix(line, "\n")) {
+			removed++
+			continue
+		}
+		kept = append(kept, line...)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".scrub-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(kept); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	// CreateTemp makes the file 0600, like the log itself.
+	if err := os.Chtimes(tmp.Name(), fi.ModTime(), fi.ModTime()); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Close closes the current file.
+func (l *File) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
@@ -0,0 +1,62 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScrub(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contact_form.log")
+	old := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	seg := path + "." + old.Format(rotatedFormat)
+	if err := os.WriteFile(seg, []byte("Email: ada@example.com |\nEmail: bob@example.com |\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(seg, old, old); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Open(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for _, line := range []string{"Email: ADA@example.com |\n", "Email: carol@example.com |\n"} {
+		if _, err := l.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := l.Scrub(func(line string) bool { return strings.Contains(strings.ToLower(line), "ada@example.com") })
+	if err != nil || n != 2 {
+		t.Fatalf("Scrub = %d, %v; want one line from each file", n, err)
+	}
+	for file, want := range map[string]string{
+		path: "Email: carol@example.com |\n",
+		seg:  "Email: bob@example.com |\n",
+	} {
+		got, err := os.ReadFile(file)
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", filepath.Base(file), got, err, want)
+		}
+		if fi, err := os.Stat(file); err != nil || fi.Mode().Perm() != 0o600 {
+			t.Errorf("%s: mode %v, %v; want 0600", filepath.Base(file), fi.Mode(), err)
+		}
+	}
+	if fi, _ := os.Stat(seg); !fi.ModTime().Equal(old) {
+		t.Errorf("segment modified %v, want its old time kept for Purge", fi.ModTime())
+	}
+
+	// The log keeps appending after a scrub.
+	if _, err := l.Write([]byte("Email: dan@example.com |\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); !strings.HasSuffix(string(got), "carol@example.com |\nEmail: dan@example.com |\n") {
+		t.Errorf("after a write the log holds %q", got)
+	}
+	if entries, _ := filepath.Glob(path + ".scrub-*"); len(entries) != 0 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+}
@@ -0,0 +1,91 @@
+// Package redact keeps personal data out of the general server log. Log
+// lines that mention a visitor pass their email address or name through
+// Email or Name, which print it according to the mode set at startup.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+)
+
+// Modes, set with LOG_REDACTION.
+const (
+	// ModeMask keeps the first letter and the email domain: "j***@acme.com".
+	// Enough to tell submissions apart when triaging, not to contact anyone.
+	ModeMask = "mask"
+	// ModeHash replaces the value with a short digest, so the log can still
+	// follow one visitor across lines without naming them.
+	ModeHash = "hash"
+	// ModeNone logs values unchanged.
+	ModeNone = "none"
+)
+
+var mode atomic.Value // string
+
+func init() {
+	mode.Store(ModeMask)
+}
+
+// SetMode switches the redaction mode; unknown modes fall back to ModeMask,
+// so a typo never turns redaction off.
+func SetMode(m string) string {
+	m = strings.ToLower(strings.TrimSpace(m))
+	if m != ModeHash && m != ModeNone {
+		m = ModeMask
+	}
+	mode.Store(m)
+	return m
+}
+
+// Mode returns the current redaction mode.
+func Mode() string {
+	return mode.Load().(string)
+}
+
+// Email returns addr as it may appear in the log.
+func Email(addr string) string {
+	switch Mode() {
+	case ModeNone:
+		return addr
+	case ModeHash:
+		return digest(strings.ToLower(strings.TrimSpace(addr)))
+	}
+	local, domain, ok := strings.Cut(addr, "@")
+	if !ok {
+		return mask(addr)
+	}
+	return mask(local) + "@" + domain
+}
+
+// Name returns a person's name as it may appear in the log: initials when
+// masking.
+func Name(name string) string {
+	switch Mode() {
+	case ModeNone:
+		return name
+	case ModeHash:
+		return digest(strings.ToLower(strings.TrimSpace(name)))
+	}
+	var initials strings.Builder
+	for _, part := range strings.Fields(name) {
+		initials.WriteString(strings.ToUpper(string([]rune(part)[:1])) + ".")
+	}
+	return initials.String()
+}
+
+func mask(s string) string {
+	if s == "" {
+		return ""
+	}
+	return string([]rune(s)[:1]) + "***"
+}
+
+func digest(s string) string {
+	if s == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "#" + hex.EncodeToString(sum[:5])
+}
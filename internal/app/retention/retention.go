@@ -0,0 +1,64 @@
+// Package retention enforces how long the site keeps personal data. A
+// Janitor runs in-process on an interval and asks each store to purge (or
+// anonymize) what has outlived its retention period.
+package retention
+
+import (
+	"log"
+	"time"
+)
+
+// Job purges one kind of record.
+type Job struct {
+	// Name appears in the janitor's log lines.
+	Name string
+	// Keep is the retention period; zero keeps records forever.
+	Keep time.Duration
+	// Purge removes or anonymizes records older than cutoff and reports
+	// how many it changed.
+	Purge func(cutoff time.Time) (int, error)
+}
+
+// Janitor runs jobs on an interval.
+type Janitor struct {
+	jobs     []Job
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewJanitor returns a janitor that runs jobs every interval.
+func NewJanitor(interval time.Duration, jobs ...Job) *Janitor {
+	return &Janitor{jobs: jobs, interval: interval, now: time.Now}
+}
+
+// Start runs the jobs once now and then on every tick, in the background.
+func (j *Janitor) Start() {
+	go func() {
+		j.Run()
+		for range time.Tick(j.interval) {
+			j.Run()
+		}
+	}()
+}
+
+// Run runs every job with a retention period once.
+func (j *Janitor) Run() {
+	now := j.now()
+	for _, job := range j.jobs {
+		if job.Keep <= 0 {
+			continue
+		}
+		n, err := job.Purge(now.Add(-job.Keep))
+		if err != nil {
+			log.Printf("retention: %s: %v", job.Name, err)
+		}
+		if n > 0 {
+			log.Printf("retention: %s: purged %d older than %s", job.Name, n, job.Keep)
+		}
+	}
+}
+
+// Days converts a retention period in days to a duration.
+func Days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
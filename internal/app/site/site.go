@@ -0,0 +1,197 @@
+// Package site is the registry of the site's top-level pages. Each entry
+// holds everything the rest of the site needs to know about a page, so the
+// routes, the sitemap, llms.txt, breadcrumbs and the header and footer
+// navigation are all generated from one list (see handler/site.go) and
+// cannot drift apart.
+package site
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Menu is a navigation menu a page can be linked from.
+type Menu int
+
+const (
+	// Header is the top-level main navigation (and the mobile menu).
+	Header Menu = iota + 1
+	// Platform is the header's platform dropdown and the footer's platform
+	// column. A link without a Tag is the menu's overview entry.
+	Platform
+	// Company is the footer's company column.
+	Company
+	// Legal is the footer's bottom row.
+	Legal
+)
+
+// NavLink places a page in one menu. Label and Desc are English message
+// IDs; render them through i18n.T.
+type NavLink struct {
+	Menu  Menu
+	Label string
+	Tag   string // short badge in the platform dropdown
+	Desc  string // one-line blurb in the platform dropdown
+}
+
+// Page is one registered page.
+type Page struct {
+	// Path is the unprefixed URL path, e.g. "/pricing".
+	Path    string
+	Handler gin.HandlerFunc
+
+	// Title and Description are the <title> and meta description, as
+	// English message IDs.
+	Title       string
+	Description string
+	// Name is the short name used in breadcrumbs and llms.txt, and Summary
+	// the one-line llms.txt description.
+	Name    string
+	Summary string
+	// Parent is the path of the page above this one in breadcrumbs; empty
+	// for pages directly under the home page.
+	Parent string
+
+	// Priority (0.0–1.0) and ChangeFreq are sitemap hints.
+	Priority   float64
+	ChangeFreq string
+
+	// Localized pages are also served under each locale's prefix.
+	Localized bool
+
+	// Dynamic pages vary with more than their URL (the contact form fills
+	// in the page the visitor came from), so they get no validators and
+	// are never answered from a cache.
+	Dynamic bool
+
+	// Images are the slugs of the illustrations the page shows, listed
+	// with it in the image sitemap (see handler/illustrations.go).
+	Images []string
+
+	// Nav lists the menus linking to the page.
+	Nav []NavLink
+}
+
+// Link is one entry of a menu: a page's path with its placement.
+type Link struct {
+	Path string
+	NavLink
+}
+
+// changeFreqs are the values the sitemap protocol allows.
+var changeFreqs = []string{"always", "hourly", "daily", "weekly", "monthly", "yearly", "never"}
+
+var registry []Page
+
+// Register sets the site's pages, in navigation order. It panics if any
+// page is missing metadata, so an incomplete entry fails at startup rather
+// than shipping a page without a title or sitemap entry.
+func Register(pages ...Page) {
+	if err := Validate(pages); err != nil {
+		panic("site: " + err.Error())
+	}
+	registry = pages
+}
+
+// Validate checks that every page has the metadata the site needs.
+func Validate(pages []Page) error {
+	var errs []error
+	seen := map[string]bool{}
+	for _, p := range pages {
+		if !strings.HasPrefix(p.Path, "/") {
+			errs = append(errs, fmt.Errorf("page %q: path must start with /", p.Path))
+			continue
+		}
+		if seen[p.Path] {
+			errs = append(errs, fmt.Errorf("page %s: registered twice", p.Path))
+		}
+		seen[p.Path] = true
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("page %s: "+format, append([]any{p.Path}, args...)...))
+		}
+		if p.Handler == nil {
+			fail("no handler")
+		}
+		for field, v := range map[string]string{"title": p.Title, "description": p.Description, "name": p.Name, "summary": p.Summary} {
+			if strings.TrimSpace(v) == "" {
+				fail("no %s", field)
+			}
+		}
+		if p.Priority <= 0 || p.Priority > 1 {
+			fail("priority %.1f outside (0, 1]", p.Priority)
+		}
+		if !contains(changeFreqs, p.ChangeFreq) {
+			fail("change frequency %q is not one of %s", p.ChangeFreq, strings.Join(changeFreqs, ", "))
+		}
+		for _, n := range p.Nav {
+			if n.Menu < Header || n.Menu > Legal {
+				fail("unknown menu %d", n.Menu)
+			}
+			if n.Label == "" {
+				fail("nav link without a label")
+			}
+		}
+	}
+	for _, p := range pages {
+		if p.Parent != "" && !seen[p.Parent] {
+			errs = append(errs, fmt.Errorf("page %s: parent %s is not registered", p.Path, p.Parent))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Pages returns the registered pages in navigation order.
+func Pages() []Page {
+	return registry
+}
+
+// Lookup returns the registered page at the unprefixed path.
+func Lookup(path string) (Page, bool) {
+	for _, p := range registry {
+		if p.Path == path {
+			return p, true
+		}
+	}
+	return Page{}, false
+}
+
+// Links returns the entries of menu m, in registry order.
+func Links(m Menu) []Link {
+	var links []Link
+	for _, p := range registry {
+		for _, n := range p.Nav {
+			if n.Menu == m {
+				links = append(links, Link{Path: p.Path, NavLink: n})
+			}
+		}
+	}
+	return links
+}
+
+// Breadcrumbs returns the trail from just below the home page down to the
+// page at path, e.g. [/features, /platform/tv-testing]; nil for the home
+// page and unregistered paths.
+func Breadcrumbs(path string) []Page {
+	var trail []Page
+	for path != "" && path != "/" && len(trail) < len(registry) {
+		p, ok := Lookup(path)
+		if !ok {
+			break
+		}
+		trail = append([]Page{p}, trail...)
+		path = p.Parent
+	}
+	return trail
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
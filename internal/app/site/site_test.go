@@ -0,0 +1,80 @@
+package site_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/izinga/robustest-web/internal/app/handler" // registers the site's pages
+	"github.com/izinga/robustest-web/internal/app/site"
+)
+
+func TestRegisteredPagesHaveMetadata(t *testing.T) {
+	pages := site.Pages()
+	if len(pages) == 0 {
+		t.Fatal("no pages registered")
+	}
+	for _, p := range pages {
+		if strings.TrimSpace(p.Title) == "" {
+			t.Errorf("%s: empty Title", p.Path)
+		}
+		if strings.TrimSpace(p.Description) == "" {
+			t.Errorf("%s: empty Description", p.Path)
+		}
+		if p.Priority == 0 {
+			t.Errorf("%s: no Priority", p.Path)
+		}
+		if p.ChangeFreq == "" {
+			t.Errorf("%s: no ChangeFreq", p.Path)
+		}
+	}
+	if err := site.Validate(pages); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestNavLinksHavePaths(t *testing.T) {
+	for _, m := range []site.Menu{site.Header, site.Platform, site.Company, site.Legal} {
+		for _, l := range site.Links(m) {
+			if l.Path == "" {
+				t.Errorf("menu %d: link %q has no path", m, l.Label)
+				continue
+			}
+			if _, ok := site.Lookup(l.Path); !ok {
+				t.Errorf("menu %d: link %q points at unregistered %s", m, l.Label, l.Path)
+			}
+		}
+	}
+}
+
+func TestIncompletePageIsRejected(t *testing.T) {
+	incomplete := site.Page{
+		Path:    "/incomplete",
+		Handler: func(*gin.Context) {},
+		Name:    "Incomplete",
+		Summary: "A page missing its title, description and sitemap hints.",
+		Nav:     []site.NavLink{{Menu: site.Company, Label: ""}},
+	}
+	err := site.Validate([]site.Page{incomplete})
+	if err == nil {
+		t.Fatal("Validate accepted a page without metadata")
+	}
+	for _, want := range []string{"no title", "no description", "priority", "change frequency", "nav link without a label"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate error %q does not mention %q", err, want)
+		}
+	}
+
+	before := len(site.Pages())
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Register did not panic on a page without metadata")
+			}
+		}()
+		site.Register(incomplete)
+	}()
+	if got := len(site.Pages()); got != before {
+		t.Errorf("Register replaced the registry with an invalid one (%d pages, was %d)", got, before)
+	}
+}
@@ -9,6 +9,7 @@ import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
 import (
+	"github.com/izinga/robustest-web/internal/app/i18n"
 	"github.com/izinga/robustest-web/internal/app/views/components"
 	"github.com/izinga/robustest-web/internal/app/views/layouts"
 )
@@ -83,11 +84,7 @@ func AboutPage() templ.Component {
 			}
 			return nil
 		})
-		templ_7745c5c3_Err = layouts.Base(
-			"About — RobusTest by Izinga Software",
-			"RobusTest is built by Izinga Software in Hyderabad, India — engineers who have run enterprise device labs since 2014 and build open-source testing tools in the open.",
-			"/about",
-		).Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = layouts.Page("/about").Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -120,31 +117,70 @@ func abStory() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = components.SectionTag("The company").Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = components.SectionTag(i18n.T(ctx, "The company")).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<h2 class=\"font-display font-bold text-3xl md:text-4xl tracking-tight\">A decade of on-premise testing.</h2><p class=\"text-muted mt-4 leading-relaxed\">We started in 2014 with a simple observation: the enterprises that most need device testing — banks, media companies, regulated industries — are exactly the ones that can't ship their builds to someone else's cloud. So we built the lab that comes to them instead.</p><p class=\"text-muted mt-4 leading-relaxed\">Everything in RobusTest exists because a real lab needed it: power-cycling a hung phone at 2 a.m., installing an enterprise build on a locked-down iPhone without a human tapping \"Trust\", capturing the exact API call that failed on a tester's device.</p></div><div class=\"grid grid-cols-2 gap-3 content-start\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<h2 class=\"font-display font-bold text-3xl md:text-4xl tracking-tight\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = components.Metric("2014", "Founded in Hyderabad, India").Render(ctx, templ_7745c5c3_Buffer)
+		var templ_7745c5c3_Var4 string
+		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(i18n.T(ctx, "A decade of on-premise testing."))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/app/views/pages/about.templ`, Line: 31, Col: 124}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</h2><p class=\"text-muted mt-4 leading-relaxed\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var5 string
+		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(i18n.T(ctx, "We started in 2014 with a simple observation: the enterprises that most need device testing — banks, media companies, regulated industries — are exactly the ones that can't ship their builds to someone else's cloud. So we built the lab that comes to them instead."))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/app/views/pages/about.templ`, Line: 33, Col: 290}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "</p><p class=\"text-muted mt-4 leading-relaxed\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var6 string
+		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(i18n.T(ctx, "Everything in RobusTest exists because a real lab needed it: power-cycling a hung phone at 2 a.m., installing an enterprise build on a locked-down iPhone without a human tapping \"Trust\", capturing the exact API call that failed on a tester's device."))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/app/views/pages/about.templ`, Line: 36, Col: 274}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</p></div><div class=\"grid grid-cols-2 gap-3 content-start\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = components.Metric("On-prem", "Every deployment, from day one").Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = components.Metric("2014", i18n.T(ctx, "Founded in Hyderabad, India")).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = components.Metric("7", "Capabilities on one platform").Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = components.Metric(i18n.T(ctx, "On-prem"), i18n.T(ctx, "Every deployment, from day one")).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = components.Metric("OSS", "Tools we publish in the open").Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = components.Metric("7", i18n.T(ctx, "Capabilities on one platform")).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</div></div></div></section>")
+		templ_7745c5c3_Err = components.Metric("OSS", i18n.T(ctx, "Tools we publish in the open")).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</div></div></div></section>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -168,20 +204,72 @@ func abOpenSource() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var4 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var4 == nil {
-			templ_7745c5c3_Var4 = templ.NopComponent
+		templ_7745c5c3_Var7 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var7 == nil {
+			templ_7745c5c3_Var7 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<section class=\"border-b border-line bg-surface\"><div class=\"max-w-6xl mx-auto px-4 sm:px-6 lg:px-8 py-16 md:py-24\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<section class=\"border-b border-line bg-surface\"><div class=\"max-w-6xl mx-auto px-4 sm:px-6 lg:px-8 py-16 md:py-24\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.SectionTag(i18n.T(ctx, "More from the team")).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "<h2 class=\"font-display font-bold text-3xl md:text-4xl tracking-tight max-w-2xl\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var8 string
+		templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(i18n.T(ctx, "We build the tools we wish existed."))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/app/views/pages/about.templ`, Line: 54, Col: 136}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</h2><p class=\"text-muted mt-4 max-w-2xl leading-relaxed\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var9 string
+		templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(i18n.T(ctx, "Beyond RobusTest, the same engineering team ships an open-source test runner and a cloud device-lab SaaS. Each stands on its own, with its own documentation and home."))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/app/views/pages/about.templ`, Line: 56, Col: 187}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</p><div class=\"grid grid-cols-1 md:grid-cols-2 gap-6 mt-10\"><a href=\"https://github.com/devicelab-dev/maestro-runner\" target=\"_blank\" rel=\"noopener noreferrer\" class=\"block border border-line-strong bg-paper p-6 hover:border-ink transition-colors\"><span class=\"font-mono text-sm font-medium\">maestro-runner ↗</span><p class=\"text-sm text-muted mt-2 leading-relaxed\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var10 string
+		templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(i18n.T(ctx, "An open-source Maestro alternative for UI test automation — a single Go binary with no JVM, covering Android, iOS, web, React Native, Flutter, and Expo. Apache 2.0 licensed."))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/app/views/pages/about.templ`, Line: 61, Col: 248}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = components.SectionTag("More from the team").Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</p></a> <a href=\"https://devicelab.dev\" target=\"_blank\" rel=\"noopener noreferrer\" class=\"block border border-line-strong bg-paper p-6 hover:border-ink transition-colors\"><span class=\"font-mono text-sm font-medium\">DeviceLab.dev ↗ <span class=\"text-xs text-muted\">SaaS</span></span><p class=\"text-sm text-muted mt-2 leading-relaxed\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var11 string
+		templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(i18n.T(ctx, "Our cloud device-lab platform — for teams that want managed devices without running a lab on their premises."))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/app/views/pages/about.templ`, Line: 65, Col: 183}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<h2 class=\"font-display font-bold text-3xl md:text-4xl tracking-tight max-w-2xl\">We build the tools we wish existed.</h2><p class=\"text-muted mt-4 max-w-2xl leading-relaxed\">Beyond RobusTest, the same engineering team ships an open-source test runner and a cloud device-lab SaaS. Each stands on its own, with its own documentation and home.</p><div class=\"grid grid-cols-1 md:grid-cols-2 gap-6 mt-10\"><a href=\"https://github.com/devicelab-dev/maestro-runner\" target=\"_blank\" rel=\"noopener noreferrer\" class=\"block border border-line-strong bg-paper p-6 hover:border-ink transition-colors\"><span class=\"font-mono text-sm font-medium\">maestro-runner ↗</span><p class=\"text-sm text-muted mt-2 leading-relaxed\">An open-source Maestro alternative for UI test automation — a single Go binary with no JVM, covering Android, iOS, web, React Native, Flutter, and Expo. Apache 2.0 licensed.</p></a> <a href=\"https://devicelab.dev\" target=\"_blank\" rel=\"noopener noreferrer\" class=\"block border border-line-strong bg-paper p-6 hover:border-ink transition-colors\"><span class=\"font-mono text-sm font-medium\">DeviceLab.dev ↗ <span class=\"text-xs text-muted\">SaaS</span></span><p class=\"text-sm text-muted mt-2 leading-relaxed\">Our cloud device-lab platform — for teams that want managed devices without running a lab on their premises.</p></a></div><div class=\"mt-12 border-t border-line pt-8\"><p class=\"font-mono text-xs uppercase tracking-widest text-muted\">Izinga Software Private Limited · IIIT Hyderabad, Gachibowli, Hyderabad 500032, India · <a href=\"mailto:hello@robustest.com\" class=\"text-trace hover:underline normal-case tracking-normal\">hello@robustest.com</a></p></div></div></section>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "</p></a></div><div class=\"mt-12 border-t border-line pt-8\"><p class=\"font-mono text-xs uppercase tracking-widest text-muted\">Izinga Software Private Limited · IIIT Hyderabad, Gachibowli, Hyderabad 500032, India · <a href=\"mailto:hello@robustest.com\" class=\"text-trace hover:underline normal-case tracking-normal\">hello@robustest.com</a></p></div></div></section>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
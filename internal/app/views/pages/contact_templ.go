@@ -9,21 +9,11 @@ import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
 import (
-	"os"
-
 	"github.com/izinga/robustest-web/internal/app/views/components"
 	"github.com/izinga/robustest-web/internal/app/views/layouts"
 )
 
-func turnstileSiteKey() string {
-	key := os.Getenv("TURNSTILE_SITE_KEY")
-	if key == "" {
-		return "1x00000000000000000000AA" // Cloudflare test key (always passes)
-	}
-	return key
-}
-
-func ContactPage(leadType string) templ.Component {
+func ContactPage(leadType string, sourcePage string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -87,30 +77,15 @@ func ContactPage(leadType string) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<div id=\"contact-form-container\" role=\"region\" aria-live=\"polite\" aria-label=\"Contact form\"><form class=\"space-y-6 mt-2\" action=\"/api/contact\" method=\"POST\" hx-post=\"/api/contact\" hx-target=\"#contact-form-container\" hx-swap=\"innerHTML\" hx-indicator=\"#submit-indicator\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<div id=\"contact-form-container\" role=\"region\" aria-live=\"polite\" aria-label=\"Contact form\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			if leadType == "partner" {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<input type=\"hidden\" name=\"lead_type\" value=\"partner\">")
-				if templ_7745c5c3_Err != nil {
-					return templ_7745c5c3_Err
-				}
-			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<div class=\"grid grid-cols-1 md:grid-cols-2 gap-6\"><div><label for=\"name\" class=\"tag block mb-2\">Name <span class=\"text-amber\" aria-hidden=\"true\">*</span> <span class=\"sr-only\">(required)</span></label> <input type=\"text\" id=\"name\" name=\"name\" required aria-required=\"true\" autocomplete=\"name\" class=\"w-full bg-surface border border-line-strong px-4 py-3 text-ink placeholder:text-muted\"></div><div><label for=\"email\" class=\"tag block mb-2\">Work email <span class=\"text-amber\" aria-hidden=\"true\">*</span> <span class=\"sr-only\">(required)</span></label> <input type=\"email\" id=\"email\" name=\"email\" required aria-required=\"true\" autocomplete=\"email\" class=\"w-full bg-surface border border-line-strong px-4 py-3 text-ink placeholder:text-muted\"></div><div><label for=\"company\" class=\"tag block mb-2\">Company</label> <input type=\"text\" id=\"company\" name=\"company\" autocomplete=\"organization\" class=\"w-full bg-surface border border-line-strong px-4 py-3 text-ink placeholder:text-muted\"></div><div><label for=\"phone\" class=\"tag block mb-2\">Phone</label> <input type=\"tel\" id=\"phone\" name=\"phone\" autocomplete=\"tel\" class=\"w-full bg-surface border border-line-strong px-4 py-3 text-ink placeholder:text-muted\"></div></div><div><label for=\"message\" class=\"tag block mb-2\">What are you testing?</label> <textarea id=\"message\" name=\"message\" rows=\"5\" placeholder=\"e.g. 25 Android + iOS devices, Appium suites in Jenkins, and an OTT app on Tizen and Roku\" class=\"w-full bg-surface border border-line-strong px-4 py-3 text-ink placeholder:text-muted\"></textarea></div><!-- Honeypot field — hidden from humans, catches bots --><div style=\"position:absolute;left:-9999px;\" aria-hidden=\"true\"><label for=\"website\">Leave this empty</label> <input type=\"text\" name=\"website\" id=\"website\" tabindex=\"-1\" autocomplete=\"off\"></div><!-- Cloudflare Turnstile widget --><div class=\"cf-turnstile\" data-sitekey=\"")
-			if templ_7745c5c3_Err != nil {
-				return templ_7745c5c3_Err
-			}
-			var templ_7745c5c3_Var3 string
-			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(turnstileSiteKey())
-			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/app/views/pages/contact.templ`, Line: 124, Col: 67}
-			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+			templ_7745c5c3_Err = components.ContactForm(components.ContactFormValues{LeadType: leadType, SourcePage: sourcePage}).Render(ctx, templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\" data-callback=\"onTurnstileSuccess\" data-theme=\"auto\"></div><button type=\"submit\" class=\"w-full md:w-auto bg-signal text-paper px-8 py-3.5 font-semibold hover:opacity-90 transition-opacity inline-flex items-center justify-center gap-2\"><span>Send it over</span> <span id=\"submit-indicator\" class=\"htmx-indicator\" role=\"status\" aria-live=\"polite\"><svg class=\"animate-spin h-5 w-5\" xmlns=\"http://www.w3.org/2000/svg\" fill=\"none\" viewBox=\"0 0 24 24\" aria-hidden=\"true\"><circle class=\"opacity-25\" cx=\"12\" cy=\"12\" r=\"10\" stroke=\"currentColor\" stroke-width=\"4\"></circle> <path class=\"opacity-75\" fill=\"currentColor\" d=\"M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 014 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z\"></path></svg> <span class=\"sr-only\">Submitting form, please wait...</span></span></button></form></div></div><div class=\"lg:col-span-2\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div></div><div class=\"lg:col-span-2\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -118,7 +93,7 @@ func ContactPage(leadType string) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div class=\"space-y-6 mt-2\"><div class=\"border border-line-strong bg-surface p-5\"><span class=\"tag\">Email</span> <a href=\"mailto:hello@robustest.com\" class=\"block font-display font-bold text-lg mt-2 text-trace hover:underline\">hello@robustest.com</a></div><div class=\"border border-line-strong bg-surface p-5\"><span class=\"tag\">Office</span><p class=\"text-sm text-muted mt-2 leading-relaxed\">IIIT Hyderabad<br>Gachibowli, Hyderabad 500032<br>India</p></div><div class=\"border border-line-strong bg-surface p-5\"><span class=\"tag\">LinkedIn</span> <a href=\"https://www.linkedin.com/company/robustest/\" target=\"_blank\" rel=\"noopener noreferrer\" class=\"block text-sm font-medium text-trace hover:underline mt-2\" aria-label=\"RobusTest on LinkedIn (opens in new window)\">linkedin.com/company/robustest ↗</a></div><div class=\"border border-line-strong bg-surface p-5\"><span class=\"tag\">What happens next</span><ul class=\"space-y-2.5 mt-3\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<div class=\"space-y-6 mt-2\"><div class=\"border border-line-strong bg-surface p-5\"><span class=\"tag\">Email</span> <a href=\"mailto:hello@robustest.com\" class=\"block font-display font-bold text-lg mt-2 text-trace hover:underline\">hello@robustest.com</a></div><div class=\"border border-line-strong bg-surface p-5\"><span class=\"tag\">Office</span><p class=\"text-sm text-muted mt-2 leading-relaxed\">IIIT Hyderabad<br>Gachibowli, Hyderabad 500032<br>India</p></div><div class=\"border border-line-strong bg-surface p-5\"><span class=\"tag\">LinkedIn</span> <a href=\"https://www.linkedin.com/company/robustest/\" target=\"_blank\" rel=\"noopener noreferrer\" class=\"block text-sm font-medium text-trace hover:underline mt-2\" aria-label=\"RobusTest on LinkedIn (opens in new window)\">linkedin.com/company/robustest ↗</a></div><div class=\"border border-line-strong bg-surface p-5\"><span class=\"tag\">What happens next</span><ul class=\"space-y-2.5 mt-3\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -134,17 +109,13 @@ func ContactPage(leadType string) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</ul></div></div></div></div></div></section>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</ul></div></div></div></div></div></section>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			return nil
 		})
-		templ_7745c5c3_Err = layouts.Base(
-			"Contact — book a demo or get a quote — RobusTest",
-			"Talk to the RobusTest team about an on-premise device lab for your organization: a live demo, a lab spec for your device list, or a license quote.",
-			"/contact",
-		).Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = layouts.Page("/contact").Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
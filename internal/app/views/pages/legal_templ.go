@@ -62,7 +62,7 @@ func LegalPage() templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<h2 class=\"font-display font-bold text-2xl md:text-3xl tracking-tight\">Privacy Policy</h2><div class=\"space-y-4 text-muted leading-relaxed mt-5\"><p>RobusTest collects basic contact information (name, email, company) when you reach out to us. We use this solely to respond to your inquiries and provide product information.</p><p><strong class=\"text-ink\">Your test data stays with you.</strong> RobusTest is an on-premise solution — all your testing data remains on your infrastructure.</p><p>We use SendGrid for email delivery. For questions, contact us at <a href=\"mailto:hello@robustest.com\" class=\"text-trace hover:underline\">hello@robustest.com</a>.</p></div></div><div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<h2 class=\"font-display font-bold text-2xl md:text-3xl tracking-tight\">Privacy Policy</h2><div class=\"space-y-4 text-muted leading-relaxed mt-5\"><p>RobusTest collects basic contact information (name, email, company) when you reach out to us. We use this solely to respond to your inquiries and provide product information.</p><p>To know which pages and campaigns bring inquiries, we keep a first-party cookie (<code>rt_attr</code>, 90 days) noting the campaign link, referring site, and landing page of your visit. It is read only if you submit the contact form, stored with your inquiry, and never shared with advertising networks.</p><p>When you contact us we email you a link to confirm the address is yours; the confirmation repeats nothing you wrote. You can get a copy of what you sent us, or have it deleted, at <a href=\"/privacy/request\" class=\"text-trace hover:underline\">robustest.com/privacy/request</a>. We send a link to the address in question, so only its owner can make the request. After a year, we remove your name, email address, phone number and message from our records of inquiries and demo bookings. Our log of contact-form submissions is deleted after 90 days, and your entries are removed from it with the rest when you ask for deletion. Votes and comments left on our docs pages are deleted after a year, and comments that mention your address go with the rest too.</p><p><strong class=\"text-ink\">Your test data stays with you.</strong> RobusTest is an on-premise solution — all your testing data remains on your infrastructure.</p><p>We use SendGrid for email delivery. For questions, contact us at <a href=\"mailto:hello@robustest.com\" class=\"text-trace hover:underline\">hello@robustest.com</a>.</p></div></div><div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -76,11 +76,7 @@ func LegalPage() templ.Component {
 			}
 			return nil
 		})
-		templ_7745c5c3_Err = layouts.Base(
-			"Privacy & Terms — RobusTest",
-			"Privacy policy and terms of service for robustest.com and the RobusTest on-premise device lab platform.",
-			"/legal",
-		).Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = layouts.Page("/legal").Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -88,11 +88,7 @@ func PartnersPage() templ.Component {
 			}
 			return nil
 		})
-		templ_7745c5c3_Err = layouts.Base(
-			"Partners — deliver on-premise device labs to your clients | RobusTest",
-			"Testing services companies run RobusTest labs at their own sites and deliver testing their clients' compliance teams can approve. You own the engagement and the client; we stay the platform. We don't do services — ever.",
-			"/partners",
-		).Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = layouts.Page("/partners").Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -27,9 +27,28 @@ document.addEventListener('DOMContentLoaded', function () {
   }
 });
 
+// First-touch attribution: the first page of a session reports where the
+// visitor came from, and the server keeps it in an HttpOnly cookie unless
+// one is already set. Done here rather than on the page response so pages
+// stay cacheable.
+(function () {
+  try {
+    if (sessionStorage.getItem('rt_attr_sent')) return;
+    sessionStorage.setItem('rt_attr_sent', '1');
+  } catch (e) { /* storage blocked: report anyway, the server dedupes */ }
+  fetch('/api/attribution', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ referrer: document.referrer, landing: location.pathname + location.search }),
+    credentials: 'same-origin',
+    keepalive: true
+  }).catch(function () {});
+})();
+
 // Analytics events — sent to Plausible (with properties) and mirrored to
-// the self-hosted GoatCounter as flattened event names. Both no-op when
-// their script is blocked or absent.
+// the first-party counter (/gc/count) as flattened event names. Both no-op
+// when their script is blocked or absent; with the built-in analytics the
+// layouts leave Plausible out, so each event is counted once.
 function track(name, props) {
   if (typeof window.plausible === 'function') {
     window.plausible(name, props ? { props: props } : undefined);
@@ -80,15 +99,47 @@ document.body.addEventListener('htmx:responseError', function (evt) {
   }
 });
 
-// After a form swap, move focus to the response for screen readers
-// and record the submission outcome.
+// Demo slots are listed in the visitor's own time zone.
+document.body.addEventListener('htmx:configRequest', function (evt) {
+  if (evt.detail.path.indexOf('/api/contact/slots') === 0 && !evt.detail.parameters.tz) {
+    try {
+      evt.detail.parameters.tz = Intl.DateTimeFormat().resolvedOptions().timeZone;
+    } catch (e) { /* the server falls back to UTC */ }
+  }
+});
+
+// The contact endpoint answers rejected submissions with 4xx/5xx and an
+// HTML fragment (an error box or the form with inline errors); swap those
+// in instead of htmx's default of discarding error responses.
+document.body.addEventListener('htmx:beforeSwap', function (evt) {
+  const target = evt.detail.target;
+  if (target && target.id === 'contact-form-container' && evt.detail.xhr.status >= 400) {
+    evt.detail.shouldSwap = true;
+    evt.detail.isError = false;
+  }
+});
+
+// After a form swap, move focus to the response (or the first invalid
+// field) for screen readers and record the submission outcome.
 document.body.addEventListener('htmx:afterSwap', function (evt) {
   const target = evt.detail.target;
   if (target && target.id === 'contact-form-container') {
+    // A re-rendered form needs a fresh Turnstile challenge.
+    if (window.turnstile) {
+      target.querySelectorAll('.cf-turnstile').forEach(function (el) {
+        if (!el.querySelector('iframe')) window.turnstile.render(el);
+      });
+    }
+    const invalid = target.querySelector('[aria-invalid="true"]');
     const response = target.querySelector('[role="alert"], .success-message, .error-message');
-    if (response) {
+    const step = target.querySelector('#contact-step-heading');
+    if (invalid) {
+      invalid.focus();
+    } else if (response) {
       response.setAttribute('tabindex', '-1');
       response.focus();
+    } else if (step) {
+      step.focus();
     }
     if (target.querySelector('.success-message')) {
       var lead = location.search.indexOf('type=partner') !== -1 ? 'partner' : 'demo';
@@ -53,17 +53,49 @@
     if (selected >= 0 && items[selected]) items[selected].scrollIntoView({ block: 'nearest' });
   }
 
+  // Searches are reported once the reader stops typing: with the number of
+  // results to /docs/search-log (the docs search report in /admin), and as
+  // a docs-search analytics event. Opening a result reports the click.
+  var countTimer = null;
+  var current = null; // { q, n } of the results on screen
+  var reported = '';
+
+  function logSearch(data) {
+    var body = JSON.stringify(data);
+    if (!navigator.sendBeacon || !navigator.sendBeacon('/docs/search-log', new Blob([body], { type: 'application/json' }))) {
+      fetch('/docs/search-log', { method: 'POST', body: body, keepalive: true }).catch(function () {});
+    }
+  }
+
+  function countSearch(q, n) {
+    current = { q: q, n: n };
+    clearTimeout(countTimer);
+    countTimer = setTimeout(function () {
+      reported = q;
+      logSearch({ q: q, n: n });
+      if (window.goatcounter && typeof window.goatcounter.count === 'function') {
+        window.goatcounter.count({ path: 'docs-search', title: q, event: true });
+      }
+    }, 1500);
+  }
+
+  results.addEventListener('click', function (e) {
+    var a = e.target.closest('a');
+    if (!a || !current) return;
+    clearTimeout(countTimer);
+    logSearch({ q: current.q, n: current.n, clicked: a.getAttribute('href'), counted: reported === current.q });
+  });
+
   function search() {
     var q = input.value.trim().toLowerCase();
     if (q.length < 2) { results.classList.add('hidden'); return; }
     loadIndex().then(function (idx) {
-      var matches = idx
+      var found = idx
         .map(function (e) { return { e: e, s: score(e, q) }; })
         .filter(function (x) { return x.s >= 0; })
-        .sort(function (a, b) { return a.s - b.s; })
-        .slice(0, 10)
-        .map(function (x) { return x.e; });
-      render(matches);
+        .sort(function (a, b) { return a.s - b.s; });
+      render(found.slice(0, 10).map(function (x) { return x.e; }));
+      countSearch(q, found.length);
     });
   }
 
@@ -90,3 +122,21 @@
     if (!results.contains(e.target) && e.target !== input) results.classList.add('hidden');
   });
 })();
+
+// First-touch attribution: the first page of a session reports where the
+// visitor came from, and the server keeps it in an HttpOnly cookie unless
+// one is already set. Done here rather than on the page response so pages
+// stay cacheable.
+(function () {
+  try {
+    if (sessionStorage.getItem('rt_attr_sent')) return;
+    sessionStorage.setItem('rt_attr_sent', '1');
+  } catch (e) { /* storage blocked: report anyway, the server dedupes */ }
+  fetch('/api/attribution', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ referrer: document.referrer, landing: location.pathname + location.search }),
+    credentials: 'same-origin',
+    keepalive: true
+  }).catch(function () {});
+})();